@@ -0,0 +1,20 @@
+action "fhirrest_operation" "reindex_patients" {
+  config {
+    operation = "$reindex"
+    parameters = {
+      "type" = "Patient"
+    }
+  }
+}
+
+# reindexes the patients every time the search parameter changes
+resource "fhirrest_fhir_resource" "patient_search_parameter" {
+  file_path = "search-parameter.json"
+
+  lifecycle {
+    action_trigger {
+      events  = [after_create, after_update]
+      actions = [action.fhirrest_operation.reindex_patients]
+    }
+  }
+}
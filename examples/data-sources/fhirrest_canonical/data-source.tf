@@ -0,0 +1,5 @@
+data "fhirrest_canonical" "gender" {
+  resource_type = "ValueSet"
+  url           = "http://hl7.org/fhir/ValueSet/administrative-gender"
+  version       = "4.0.1"
+}
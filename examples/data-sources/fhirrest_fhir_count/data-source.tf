@@ -0,0 +1,13 @@
+data "fhirrest_fhir_count" "questionnaires" {
+  resource_type = "Questionnaire"
+  search_parameters = {
+    "status" = "active"
+  }
+}
+
+check "questionnaires_loaded" {
+  assert {
+    condition     = data.fhirrest_fhir_count.questionnaires.total > 0
+    error_message = "No active Questionnaire found on the server"
+  }
+}
@@ -0,0 +1,11 @@
+data "fhirrest_fhir_resource_exists" "organization" {
+  resource_type = "Organization"
+  search_parameters = {
+    "identifier" = "http://example.com/organizations|acme"
+  }
+}
+
+resource "fhirrest_fhir_resource" "organization" {
+  count     = data.fhirrest_fhir_resource_exists.organization.exists ? 0 : 1
+  file_path = "${path.cwd}/organization.json"
+}
@@ -0,0 +1,7 @@
+data "fhirrest_fhir_resource_history" "questionnaire" {
+  resource_id = fhirrest_fhir_resource.questionnaire.resource_id
+}
+
+output "questionnaire_versions" {
+  value = [for version in data.fhirrest_fhir_resource_history.questionnaire.versions : version.version_id]
+}
@@ -0,0 +1,10 @@
+data "fhirrest_fhir_resource_ids" "questionnaires" {
+  resource_type = "Questionnaire"
+  search_parameters = {
+    "status" = "draft"
+  }
+}
+
+output "draft_questionnaires" {
+  value = data.fhirrest_fhir_resource_ids.questionnaires.ids
+}
@@ -0,0 +1,19 @@
+data "fhirrest_fhir_search" "active_organizations" {
+  resource_type = "Organization"
+  search_parameters = {
+    "active" = "true"
+    "name"   = "Acme"
+  }
+}
+
+data "fhirrest_fhir_search" "medication_requests" {
+  resource_type = "MedicationRequest"
+  search_parameters = {
+    "status" = "active"
+  }
+  include = ["MedicationRequest:medication"]
+}
+
+output "medications" {
+  value = data.fhirrest_fhir_search.medication_requests.included["Medication"]
+}
@@ -0,0 +1,5 @@
+data "fhirrest_fhir_security" "server" {}
+
+output "token_url" {
+  value = data.fhirrest_fhir_security.server.token_url
+}
@@ -0,0 +1,3 @@
+data "fhirrest_graphql" "patients" {
+  query = "{ PatientList(name: \"smith\") { id name { given family } } }"
+}
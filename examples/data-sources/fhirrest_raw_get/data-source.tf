@@ -0,0 +1,10 @@
+data "fhirrest_raw_get" "patients" {
+  path = "Patient?name=foo&_format=json"
+
+  lifecycle {
+    postcondition {
+      condition     = self.status_code == 200
+      error_message = "The search failed: ${self.body}"
+    }
+  }
+}
@@ -0,0 +1,4 @@
+data "fhirrest_resolve_reference" "observation_subject" {
+  resource_id    = "Observation/08146022-932a-4001-9fe4-928382855ddf"
+  reference_path = "Observation.subject"
+}
@@ -0,0 +1,12 @@
+ephemeral "fhirrest_fhir_resource" "test_patient" {
+  resource_id = "Patient/test-patient-1"
+}
+
+# the demographics of the patient only reach the server through the write-only content_wo, never the state
+resource "fhirrest_fhir_resource" "fixture_patient" {
+  content_wo = jsonencode(merge(jsondecode(ephemeral.fhirrest_fhir_resource.test_patient.resource), {
+    id = "fixture-patient-1"
+  }))
+  content_wo_version = 1
+  create_method      = "put"
+}
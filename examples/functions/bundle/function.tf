@@ -0,0 +1,9 @@
+resource "fhirrest_rest" "transaction" {
+  create = {
+    path = "/"
+    body = provider::fhirrest::bundle([
+      { method = "PUT", url = "Patient/123", resource = file("patient.json") },
+      { method = "DELETE", url = "Observation/456", resource = null },
+    ])
+  }
+}
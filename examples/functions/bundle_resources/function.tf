@@ -0,0 +1,10 @@
+data "fhirrest_fhir_search" "active_organizations" {
+  resource_type = "Organization"
+  search_parameters = {
+    "active" = "true"
+  }
+}
+
+locals {
+  organizations = [for resource in provider::fhirrest::bundle_resources(data.fhirrest_fhir_search.active_organizations.bundle) : jsondecode(resource)]
+}
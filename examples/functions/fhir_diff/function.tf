@@ -0,0 +1,6 @@
+check "patient_in_sync" {
+  assert {
+    condition     = length(provider::fhirrest::fhir_diff(file("patient.json"), fhirrest_fhir_resource.patient.response_body, ["id", "meta", "text"])) == 0
+    error_message = "The Patient on the server differs from patient.json"
+  }
+}
@@ -0,0 +1,7 @@
+data "fhirrest_fhir_resource" "patient" {
+  resource_id = "Patient/08146022-932a-4001-9fe4-928382855ddf"
+}
+
+locals {
+  family_names = provider::fhirrest::fhirpath(data.fhirrest_fhir_resource.patient.resource, "Patient.name.where(use = 'official').family")
+}
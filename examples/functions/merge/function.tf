@@ -0,0 +1,5 @@
+resource "fhirrest_fhir_resource" "endpoint" {
+  content = provider::fhirrest::merge(file("${path.module}/endpoint.json"), jsonencode({
+    address = "https://test.example.com/fhir"
+  }))
+}
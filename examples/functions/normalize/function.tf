@@ -0,0 +1,4 @@
+resource "fhirrest_fhir_resource" "organization" {
+  file_path   = "${path.module}/organization.json"
+  file_sha256 = sha256(provider::fhirrest::normalize(file("${path.module}/organization.json")))
+}
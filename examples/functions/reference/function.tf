@@ -0,0 +1,7 @@
+resource "fhirrest_fhir_resource" "organization" {
+  file_path = "${path.module}/organization.json"
+}
+
+locals {
+  managing_organization = provider::fhirrest::reference(fhirrest_fhir_resource.organization.resource_id, "Acme")
+}
@@ -0,0 +1,10 @@
+locals {
+  encounter_references = provider::fhirrest::references(file("${path.module}/encounter.json"))
+}
+
+check "referenced_resources_are_managed" {
+  assert {
+    condition     = alltrue([for reference in local.encounter_references : contains(values(fhirrest_fhir_resource.all)[*].resource_id, reference)])
+    error_message = "The encounter references resources not managed by this configuration"
+  }
+}
@@ -0,0 +1,3 @@
+resource "fhirrest_fhir_resource" "organization" {
+  content = provider::fhirrest::remove_path(file("${path.module}/organization.json"), "text")
+}
@@ -0,0 +1,4 @@
+import {
+  to = fhirrest_fhir_resource.patient
+  id = provider::fhirrest::search_query("Patient", { "identifier" = "http://example.com/mrn|123" })
+}
@@ -0,0 +1,3 @@
+resource "fhirrest_fhir_resource" "organization" {
+  content = provider::fhirrest::set_path(file("${path.module}/organization.json"), "identifier.0.value", jsonencode(var.organization_code))
+}
@@ -0,0 +1,7 @@
+resource "fhirrest_fhir_resource" "patient" {
+  create_method = "put"
+  content = jsonencode({
+    resourceType = "Patient"
+    id           = provider::fhirrest::uuid5("url", "http://example.com/mrn|123")
+  })
+}
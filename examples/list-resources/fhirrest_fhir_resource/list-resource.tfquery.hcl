@@ -0,0 +1,11 @@
+list "fhirrest_fhir_resource" "active_questionnaires" {
+  provider         = fhirrest
+  include_resource = true
+
+  config {
+    resource_type = "Questionnaire"
+    search_parameters = {
+      "status" = "active"
+    }
+  }
+}
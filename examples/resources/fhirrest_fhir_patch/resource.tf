@@ -0,0 +1,7 @@
+resource "fhirrest_fhir_patch" "deactivate_vendor_organization" {
+  resource_id = "Organization/vendor-managed"
+  patch = jsonencode([
+    { op = "replace", path = "/active", value = false },
+    { op = "add", path = "/alias", value = ["Legacy vendor"] },
+  ])
+}
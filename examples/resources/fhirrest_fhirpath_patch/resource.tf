@@ -0,0 +1,16 @@
+resource "fhirrest_fhirpath_patch" "vendor_organization" {
+  resource_id = "Organization/vendor-managed"
+  operations = [
+    {
+      type  = "replace"
+      path  = "Organization.active"
+      value = jsonencode(false)
+    },
+    {
+      type  = "add"
+      path  = "Organization"
+      name  = "alias"
+      value = jsonencode("Legacy vendor")
+    },
+  ]
+}
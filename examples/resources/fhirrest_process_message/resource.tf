@@ -0,0 +1,4 @@
+resource "fhirrest_process_message" "admit" {
+  file_path   = "${path.cwd}/admit-message.json"
+  file_sha256 = sha256(file("${path.cwd}/admit-message.json"))
+}
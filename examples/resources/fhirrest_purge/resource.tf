@@ -0,0 +1,6 @@
+resource "fhirrest_purge" "test_patients" {
+  resource_type = "Patient"
+  search_parameters = {
+    "_tag" = "http://example.com/tags|test-data"
+  }
+}
@@ -0,0 +1,9 @@
+resource "fhirrest_rest" "reindex" {
+  create = {
+    path = "$mark-all-resources-for-reindexing"
+    body = jsonencode({
+      resourceType = "Parameters"
+      parameter    = [{ name = "type", valueString = "Patient" }]
+    })
+  }
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &BundleFunction{}
+
+// bundleEntryMethods are the methods of the requests of transaction Bundle entries.
+var bundleEntryMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "PATCH"}
+
+func NewBundleFunction() function.Function {
+	return &BundleFunction{}
+}
+
+// BundleFunction defines the function that builds a transaction Bundle.
+type BundleFunction struct{}
+
+// bundleFunctionEntry is an entry given to the bundle function.
+type bundleFunctionEntry struct {
+	Method   string  `tfsdk:"method"`
+	Url      string  `tfsdk:"url"`
+	Resource *string `tfsdk:"resource"`
+}
+
+func (f *BundleFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "bundle"
+}
+
+func (f *BundleFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Builds a transaction Bundle",
+		MarkdownDescription: "Builds a transaction Bundle json string from a list of entries, each one with the method and the url of its request and the resource as json string, null for the requests without a body like `DELETE`",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name: "entries",
+				ElementType: types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"method":   types.StringType,
+						"url":      types.StringType,
+						"resource": types.StringType,
+					},
+				},
+				MarkdownDescription: "The entries of the Bundle, example `[{ method = \"PUT\", url = \"Patient/123\", resource = file(\"patient.json\") }]`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *BundleFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var entries []bundleFunctionEntry
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &entries))
+	if resp.Error != nil {
+		return
+	}
+
+	type bundleRequest struct {
+		Method string `json:"method"`
+		Url    string `json:"url"`
+	}
+	type bundleEntry struct {
+		Resource json.RawMessage `json:"resource,omitempty"`
+		Request  bundleRequest   `json:"request"`
+	}
+	bundle := struct {
+		ResourceType string        `json:"resourceType"`
+		Type         string        `json:"type"`
+		Entry        []bundleEntry `json:"entry"`
+	}{
+		ResourceType: "Bundle",
+		Type:         "transaction",
+		Entry:        make([]bundleEntry, 0, len(entries)),
+	}
+	for i, entry := range entries {
+		method := strings.ToUpper(entry.Method)
+		if !slices.Contains(bundleEntryMethods, method) {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("the method %s of the entry %d is not one of %s", entry.Method, i, strings.Join(bundleEntryMethods, ", ")))
+			return
+		}
+		bundleEntry := bundleEntry{Request: bundleRequest{Method: method, Url: entry.Url}}
+		if entry.Resource != nil {
+			if !json.Valid([]byte(*entry.Resource)) {
+				resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("the resource of the entry %d is not valid json", i))
+				return
+			}
+			bundleEntry.Resource = json.RawMessage(*entry.Resource)
+		}
+		bundle.Entry = append(bundle.Entry, bundleEntry)
+	}
+	bundleJson, err := json.Marshal(bundle)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(bundleJson)))
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &BundleResourcesFunction{}
+
+func NewBundleResourcesFunction() function.Function {
+	return &BundleResourcesFunction{}
+}
+
+// BundleResourcesFunction defines the function that returns the resources of the entries of a Bundle.
+type BundleResourcesFunction struct{}
+
+func (f *BundleResourcesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "bundle_resources"
+}
+
+func (f *BundleResourcesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the resources of the entries of a Bundle",
+		MarkdownDescription: "Returns the resources of the entries of a Bundle json string, each one as json string. Entries without a resource are left out",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "bundle_json",
+				MarkdownDescription: "The Bundle as json string, example the bundle attribute of the fhirrest_fhir_search data source",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *BundleResourcesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var bundleJson string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &bundleJson))
+	if resp.Error != nil {
+		return
+	}
+
+	bundle, err := parseBundle([]byte(bundleJson))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the Bundle: %s", err.Error()))
+		return
+	}
+
+	resources := make([]string, 0, len(bundle.Entry))
+	for _, entry := range bundle.Entry {
+		if len(entry.Resource) == 0 {
+			continue
+		}
+		resources = append(resources, string(entry.Resource))
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, resources))
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// defaultAzureMaxBundleEntries is the most entries the Azure FHIR service accepts in a batch or transaction Bundle.
+const defaultAzureMaxBundleEntries = 500
+
+// FhirAzureModel describes the azure provider attribute.
+type FhirAzureModel struct {
+	HardDelete       types.Bool  `tfsdk:"hard_delete"`
+	PurgeHistory     types.Bool  `tfsdk:"purge_history"`
+	MaxBundleEntries types.Int64 `tfsdk:"max_bundle_entries"`
+	VersionedReads   types.Bool  `tfsdk:"versioned_reads"`
+}
+
+// AzureSettings enables the behaviors specific to the Azure Health Data Services FHIR service. The zero value, used when
+// the azure attribute is not set, disables all of them.
+type AzureSettings struct {
+	HardDelete   bool
+	PurgeHistory bool
+	// MaxBundleEntries is the most entries of the batch and transaction Bundles sent, 0 when not limited.
+	MaxBundleEntries int64
+	VersionedReads   bool
+}
+
+func azureSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Enables the compatibility mode for the FHIR service of Azure Health Data Services, set it to `{}` for the defaults",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"hard_delete": schema.BoolAttribute{
+				MarkdownDescription: "Deletes the resources of `fhirrest_fhir_resource` with `_hardDelete=true`, removing them with their history instead of the soft delete of the service, so they can be created again with the same id",
+				Optional:            true,
+			},
+			"purge_history": schema.BoolAttribute{
+				MarkdownDescription: "After each update of a `fhirrest_fhir_resource`, sends `$purge-history` to remove the previous versions of the resource",
+				Optional:            true,
+			},
+			"max_bundle_entries": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The batch and transaction Bundles with more entries fail before being sent, instead of being rejected by the service. Defaults to %d", defaultAzureMaxBundleEntries),
+				Optional:            true,
+			},
+			"versioned_reads": schema.BoolAttribute{
+				MarkdownDescription: "After each create and update, reads the version written with a versioned read (vread) of the Location returned by the service, instead of trusting the response body. Defaults to true",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newAzureSettings builds the Azure settings from the provider configuration, using the defaults for the unset values.
+func newAzureSettings(data *FhirAzureModel) AzureSettings {
+	if data == nil {
+		return AzureSettings{}
+	}
+	settings := AzureSettings{
+		HardDelete:       data.HardDelete.ValueBool(),
+		PurgeHistory:     data.PurgeHistory.ValueBool(),
+		MaxBundleEntries: defaultAzureMaxBundleEntries,
+		VersionedReads:   data.VersionedReads.IsNull() || data.VersionedReads.ValueBool(),
+	}
+	if !data.MaxBundleEntries.IsNull() {
+		settings.MaxBundleEntries = data.MaxBundleEntries.ValueInt64()
+	}
+	return settings
+}
+
+// checkBundleSize returns an error when the body is a batch or transaction Bundle with more entries than the server accepts.
+func checkBundleSize(providerSettings *ProviderSettings, requestBody []byte) error {
+	if providerSettings.Azure.MaxBundleEntries <= 0 || len(requestBody) == 0 {
+		return nil
+	}
+	var bundle struct {
+		ResourceType string            `json:"resourceType"`
+		Type         string            `json:"type"`
+		Entry        []json.RawMessage `json:"entry"`
+	}
+	if err := json.Unmarshal(requestBody, &bundle); err != nil || bundle.ResourceType != "Bundle" || (bundle.Type != "batch" && bundle.Type != "transaction") {
+		return nil
+	}
+	if int64(len(bundle.Entry)) > providerSettings.Azure.MaxBundleEntries {
+		return fmt.Errorf("the %s Bundle has %d entries, more than the %d accepted by the server, split it in smaller Bundles", bundle.Type, len(bundle.Entry), providerSettings.Azure.MaxBundleEntries)
+	}
+	return nil
+}
+
+// purgeHistory removes the previous versions of a resource from the Azure FHIR service. A failure is reported as a warning,
+// as the resource was already written.
+func purgeHistory(ctx context.Context, providerSettings *ProviderSettings, baseUrl string, resourceId string, diag *diag.Diagnostics) {
+	url := fmt.Sprintf("%s/%s/$purge-history", baseUrl, resourceId)
+	response, err := DoFhirRequest(ctx, providerSettings, "DELETE", url, nil)
+	if err != nil {
+		diag.AddWarning(fmt.Sprintf("the resource %s was written but its history could not be purged", resourceId), err.Error())
+		return
+	}
+	if response.Status[0] != '2' {
+		diag.AddWarning(fmt.Sprintf("the resource %s was written but its history could not be purged, the server answered %s", resourceId, response.Status), string(response.Body))
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("purged the history of the resource %s", resourceId))
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// baselinePrivateStateKey is the private state key of the last resource read from the server.
+const baselinePrivateStateKey = "baseline"
+
+// fhirResourceBaseline is the last representation of the resource seen on the server, kept in the private state so the
+// (possibly sensitive) body is not exposed as an attribute. The ignored fields are left out of the resource.
+type fhirResourceBaseline struct {
+	VersionId string          `json:"versionId"`
+	Resource  json.RawMessage `json:"resource"`
+}
+
+// privateStateReader and privateStateWriter are satisfied by the private state of the requests and responses of the framework.
+type privateStateReader interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+type privateStateWriter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// setBaseline keeps the resource returned by the server as baseline of the next plan and update.
+func setBaseline(ctx context.Context, private privateStateWriter, body []byte, ignoreFields []string, diag *diag.Diagnostics) {
+	baseline, err := json.Marshal(fhirResourceBaseline{
+		VersionId: resourceVersionId(body),
+		Resource:  json.RawMessage(importedContent(body, ignoreFields)),
+	})
+	if err != nil {
+		return
+	}
+	diag.Append(private.SetKey(ctx, baselinePrivateStateKey, baseline)...)
+}
+
+// getBaseline returns the baseline kept in the private state, or nil when there is none, like on states written by older versions.
+func getBaseline(ctx context.Context, private privateStateReader) *fhirResourceBaseline {
+	value, diags := private.GetKey(ctx, baselinePrivateStateKey)
+	if diags.HasError() || len(value) == 0 {
+		return nil
+	}
+	var baseline fhirResourceBaseline
+	if err := json.Unmarshal(value, &baseline); err != nil || len(baseline.Resource) == 0 {
+		return nil
+	}
+	return &baseline
+}
+
+// plannedPatch returns the json patch turning the baseline into the content of the file, or nil when the baseline already holds it.
+// The second value is false when the content can not be read or parsed, leaving the error to the apply.
+func plannedPatch(settings FhirResourceSettings, providerSettings *ProviderSettings, baseline *fhirResourceBaseline) ([]byte, bool) {
+	content := []byte(settings.Content)
+	if settings.Content == "" {
+		var err error
+		if content, err = os.ReadFile(settings.FhirResourceFilePath); err != nil {
+			return nil, false
+		}
+	}
+	patch, _, err := buildPatch(updateMethodJsonPatch, baseline.Resource, withManagedTag(replaceValues(content, settings.Substitutions), providerSettings), settings.IgnoreFields)
+	if err != nil {
+		return nil, false
+	}
+	return patch, true
+}
+
+// patchPaths lists the operations of a json patch without their values, one per line, example `replace /name/0/family`.
+func patchPaths(patch []byte) string {
+	var operations []struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		lines = append(lines, fmt.Sprintf("%s %s", operation.Op, operation.Path))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirCanonicalDataSource{}
+
+func NewFhirCanonicalDataSource() datasource.DataSource {
+	return &FhirCanonicalDataSource{}
+}
+
+// FhirCanonicalDataSource defines the data source that resolves a conformance resource by its canonical url.
+type FhirCanonicalDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirCanonicalDataSourceModel describes the data source data model.
+type FhirCanonicalDataSourceModel struct {
+	ResourceType types.String `tfsdk:"resource_type"`
+	Url          types.String `tfsdk:"url"`
+	Version      types.String `tfsdk:"version"`
+	FhirBaseUrl  types.String `tfsdk:"fhir_base_url"`
+	Headers      types.Map    `tfsdk:"headers"`
+
+	// state
+	Resource   types.String `tfsdk:"resource"`
+	ResourceId types.String `tfsdk:"resource_id"`
+}
+
+func (d *FhirCanonicalDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_canonical"
+}
+
+func (d *FhirCanonicalDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source resolves a conformance resource, like a StructureDefinition, ValueSet, CodeSystem or Questionnaire, by its canonical url and optional version",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the conformance resource, example ValueSet",
+				Required:            true,
+			},
+			"url": schema.StringAttribute{
+				MarkdownDescription: "The canonical url of the resource, example http://hl7.org/fhir/ValueSet/administrative-gender",
+				Required:            true,
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: "The business version of the resource. Required when the server holds several versions of the canonical url",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"resource": schema.StringAttribute{
+				MarkdownDescription: "The conformance resource as json string",
+				Computed:            true,
+			},
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the resource on the server, example ValueSet/administrative-gender",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirCanonicalDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirCanonicalDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirCanonicalDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	searchParameters := map[string]string{"url": data.Url.ValueString()}
+	if !data.Version.IsNull() {
+		searchParameters["version"] = data.Version.ValueString()
+	}
+
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+	body, shouldReturn := SendFhirRequest(ctx, d.providerSettings, "GET", searchUrl, nil, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+
+	bundle, err := parseBundle(body)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+		return
+	}
+
+	resources := bundle.MatchResources()
+	ids := bundle.ResourceIds()
+	switch {
+	case len(resources) == 0 || len(ids) == 0:
+		resp.Diagnostics.AddError(fmt.Sprintf("no %s found with the canonical url %s", data.ResourceType.ValueString(), data.Url.ValueString()), fmt.Sprintf("The search %s returned no resources", searchUrl))
+		return
+	case len(resources) > 1:
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("found %d %s resources with the canonical url %s", len(resources), data.ResourceType.ValueString(), data.Url.ValueString()),
+			"Set the version to select a single resource",
+		)
+		return
+	}
+
+	data.Resource = d.providerSettings.stateBody(resources[0])
+	data.ResourceId = types.StringValue(ids[0])
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// FhirCapabilityStatement holds the parts of the CapabilityStatement used by the provider.
+type FhirCapabilityStatement struct {
+	FhirVersion string `json:"fhirVersion"`
+	Software    struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"software"`
+	Rest []struct {
+		Mode     string            `json:"mode"`
+		Security *FhirRestSecurity `json:"security"`
+		Resource []struct {
+			Type        string `json:"type"`
+			Interaction []struct {
+				Code string `json:"code"`
+			} `json:"interaction"`
+			ConditionalCreate bool   `json:"conditionalCreate"`
+			ConditionalRead   string `json:"conditionalRead"`
+			ConditionalUpdate bool   `json:"conditionalUpdate"`
+			ConditionalDelete string `json:"conditionalDelete"`
+		} `json:"resource"`
+		Interaction []struct {
+			Code string `json:"code"`
+		} `json:"interaction"`
+	} `json:"rest"`
+}
+
+// FhirRestSecurity holds the rest.security element of the CapabilityStatement.
+type FhirRestSecurity struct {
+	Cors    *bool `json:"cors"`
+	Service []struct {
+		Coding []struct {
+			System string `json:"system"`
+			Code   string `json:"code"`
+		} `json:"coding"`
+		Text string `json:"text"`
+	} `json:"service"`
+	Description string `json:"description"`
+	Extension   []struct {
+		Url       string `json:"url"`
+		Extension []struct {
+			Url      string `json:"url"`
+			ValueUri string `json:"valueUri"`
+		} `json:"extension"`
+	} `json:"extension"`
+}
+
+// FhirRequiredCapabilitiesModel describes the required_capabilities provider attribute.
+type FhirRequiredCapabilitiesModel struct {
+	SystemInteractions   types.List `tfsdk:"system_interactions"`
+	ResourceTypes        types.List `tfsdk:"resource_types"`
+	ResourceInteractions types.Map  `tfsdk:"resource_interactions"`
+}
+
+func requiredCapabilitiesSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Capabilities the server must declare in its CapabilityStatement (/metadata). When set, the provider fails early if the server does not support them",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"system_interactions": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The required system interactions, example `[\"transaction\", \"batch\"]`",
+				Optional:            true,
+			},
+			"resource_types": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The resource types the server must support, example `[\"Questionnaire\", \"ValueSet\"]`",
+				Optional:            true,
+			},
+			"resource_interactions": schema.MapAttribute{
+				ElementType:         basetypes.ListType{ElemType: basetypes.StringType{}},
+				MarkdownDescription: "The interactions required per resource type, example `{ Patient = [\"update\", \"conditional-create\"] }`. Besides the interaction codes, `conditional-create`, `conditional-read`, `conditional-update` and `conditional-delete` are supported",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// ReadCapabilityStatement reads the CapabilityStatement of the server from the /metadata endpoint.
+func ReadCapabilityStatement(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, diag *diag.Diagnostics) (*FhirCapabilityStatement, []byte) {
+	body, shouldReturn := ReadFhirResource(ctx, providerSettings, resourceBaseUrl, "metadata", diag)
+	if shouldReturn {
+		return nil, nil
+	}
+	var capabilityStatement FhirCapabilityStatement
+	if err := json.Unmarshal(body, &capabilityStatement); err != nil {
+		diag.AddError("failed to unmarshal the CapabilityStatement of the server", err.Error())
+		return nil, nil
+	}
+	return &capabilityStatement, body
+}
+
+// assertCapabilities reports in diag every required capability missing in the CapabilityStatement of the server.
+func assertCapabilities(ctx context.Context, providerSettings *ProviderSettings, required *FhirRequiredCapabilitiesModel, diag *diag.Diagnostics) {
+	var systemInteractions, resourceTypes []string
+	resourceInteractions := make(map[string][]string)
+	diag.Append(required.SystemInteractions.ElementsAs(ctx, &systemInteractions, true)...)
+	diag.Append(required.ResourceTypes.ElementsAs(ctx, &resourceTypes, true)...)
+	diag.Append(required.ResourceInteractions.ElementsAs(ctx, &resourceInteractions, true)...)
+	if diag.HasError() {
+		return
+	}
+
+	capabilityStatement, _ := ReadCapabilityStatement(ctx, providerSettings, nil, diag)
+	if capabilityStatement == nil {
+		return
+	}
+
+	supported := capabilityStatement.supportedInteractions()
+	var missing []string
+	for _, interaction := range systemInteractions {
+		if !slices.Contains(supported[""], interaction) {
+			missing = append(missing, fmt.Sprintf("system interaction %s", interaction))
+		}
+	}
+	for _, resourceType := range resourceTypes {
+		if _, ok := supported[resourceType]; !ok {
+			missing = append(missing, fmt.Sprintf("resource type %s", resourceType))
+		}
+	}
+	for resourceType, interactions := range resourceInteractions {
+		for _, interaction := range interactions {
+			if !slices.Contains(supported[resourceType], interaction) {
+				missing = append(missing, fmt.Sprintf("interaction %s on %s", interaction, resourceType))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		diag.AddError(
+			fmt.Sprintf("the FHIR server %s does not support the required capabilities", providerSettings.FhirBaseUrl),
+			fmt.Sprintf("Missing in the CapabilityStatement: %v", missing),
+		)
+	}
+}
+
+// supportedInteractions returns the interactions supported by the server, keyed by resource type.
+// The system interactions use the empty key.
+func (c *FhirCapabilityStatement) supportedInteractions() map[string][]string {
+	supported := map[string][]string{"": {}}
+	for _, rest := range c.Rest {
+		if rest.Mode != "" && rest.Mode != "server" {
+			continue
+		}
+		for _, interaction := range rest.Interaction {
+			supported[""] = append(supported[""], interaction.Code)
+		}
+		for _, resource := range rest.Resource {
+			interactions := supported[resource.Type]
+			for _, interaction := range resource.Interaction {
+				interactions = append(interactions, interaction.Code)
+			}
+			if resource.ConditionalCreate {
+				interactions = append(interactions, "conditional-create")
+			}
+			if resource.ConditionalUpdate {
+				interactions = append(interactions, "conditional-update")
+			}
+			if resource.ConditionalRead != "" && resource.ConditionalRead != "not-supported" {
+				interactions = append(interactions, "conditional-read")
+			}
+			if resource.ConditionalDelete != "" && resource.ConditionalDelete != "not-supported" {
+				interactions = append(interactions, "conditional-delete")
+			}
+			supported[resource.Type] = interactions
+		}
+	}
+	return supported
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultCircuitBreakerThreshold is the amount of consecutive failed requests after which a server is considered unavailable.
+const defaultCircuitBreakerThreshold = 5
+
+// circuitBreakerCooldown is the delay after which a single request is sent again to a server considered unavailable,
+// checking if it recovered.
+const circuitBreakerCooldown = 30 * time.Second
+
+// circuitBreaker tracks the consecutive failed requests of each server, failing the next requests fast once a server
+// failed too many times in a row instead of letting each remaining operation time out on its own. Once the cooldown is
+// over a single probe request is let through: its success closes the circuit again, its failure keeps it open for
+// another cooldown.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	threshold int64
+	servers   map[string]*serverHealth
+}
+
+// serverHealth is the failure count of a server and, once the circuit is open, when its last request was let through.
+type serverHealth struct {
+	failures int64
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int64) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		servers:   make(map[string]*serverHealth),
+	}
+}
+
+// allow returns an error when the server of the url is considered unavailable, letting one probe request through
+// each cooldown.
+func (b *circuitBreaker) allow(requestUrl string) error {
+	if b == nil {
+		return nil
+	}
+	server := serverOf(requestUrl)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	health := b.servers[server]
+	if health == nil || health.failures < b.threshold {
+		return nil
+	}
+	if time.Since(health.openedAt) >= circuitBreakerCooldown {
+		// The next probe waits for a new cooldown, whatever the outcome of this one, so a cancelled probe never blocks the server.
+		health.openedAt = time.Now()
+		return nil
+	}
+	return fmt.Errorf("the server %s is unavailable, its last %d requests failed. The request was not sent, the server is tried again %s after its last failure", server, health.failures, circuitBreakerCooldown)
+}
+
+// record counts a failed request of the server of the url, or resets the count on success.
+func (b *circuitBreaker) record(requestUrl string, failed bool) {
+	if b == nil {
+		return
+	}
+	server := serverOf(requestUrl)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !failed {
+		delete(b.servers, server)
+		return
+	}
+	health := b.servers[server]
+	if health == nil {
+		health = &serverHealth{}
+		b.servers[server] = health
+	}
+	health.failures++
+	if health.failures >= b.threshold {
+		health.openedAt = time.Now()
+	}
+}
+
+// serverOf returns the scheme and host of a url, identifying the server it points to.
+func serverOf(requestUrl string) string {
+	parsed, err := url.Parse(requestUrl)
+	if err != nil || parsed.Host == "" {
+		return requestUrl
+	}
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultRequestTimeout bounds each http request when the provider does not configure a request_timeout.
+const defaultRequestTimeout = 60 * time.Second
+
+const (
+	// defaultMaxIdleConnsPerHost matches the default parallelism of terraform, so concurrent operations reuse their connections.
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+)
+
+// FhirConnectionPoolModel describes the connection_pool provider attribute.
+type FhirConnectionPoolModel struct {
+	MaxIdleConnsPerHost types.Int64  `tfsdk:"max_idle_conns_per_host"`
+	IdleConnTimeout     types.String `tfsdk:"idle_conn_timeout"`
+	KeepAlive           types.String `tfsdk:"keep_alive"`
+	DisableKeepAlives   types.Bool   `tfsdk:"disable_keep_alives"`
+}
+
+func connectionPoolSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Tuning of the connections kept open to the fhir servers",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"max_idle_conns_per_host": schema.Int64Attribute{
+				MarkdownDescription: "The maximum idle connections kept open to each server. Defaults to 10",
+				Optional:            true,
+			},
+			"idle_conn_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long an idle connection is kept open, example 2m. Defaults to 90s",
+				Optional:            true,
+			},
+			"keep_alive": schema.StringAttribute{
+				MarkdownDescription: "The interval of the tcp keep-alive probes of the open connections, example 15s. Defaults to 30s",
+				Optional:            true,
+			},
+			"disable_keep_alives": schema.BoolAttribute{
+				MarkdownDescription: "Opens a new connection for each request instead of reusing them",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newHttpClient builds the http client used for every request to the fhir servers from the provider configuration.
+func newHttpClient(data FhirRestProviderModel, diag *diag.Diagnostics) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	switch data.HttpVersion.ValueString() {
+	case "1.1":
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		transport.Protocols = protocols
+	case "2":
+		// Without HTTP/1 the requests fail on servers not supporting HTTP/2, and http servers are sent HTTP/2 in clear text (h2c).
+		protocols := new(http.Protocols)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		transport.Protocols = protocols
+	}
+
+	pool := data.ConnectionPool
+	if pool == nil {
+		pool = &FhirConnectionPoolModel{}
+	}
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if !pool.MaxIdleConnsPerHost.IsNull() {
+		transport.MaxIdleConnsPerHost = int(pool.MaxIdleConnsPerHost.ValueInt64())
+	}
+	transport.IdleConnTimeout = parseDurationAttribute(pool.IdleConnTimeout, defaultIdleConnTimeout, "connection_pool.idle_conn_timeout", diag)
+	transport.DisableKeepAlives = pool.DisableKeepAlives.ValueBool()
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: parseDurationAttribute(pool.KeepAlive, defaultKeepAlive, "connection_pool.keep_alive", diag),
+	}
+	transport.DialContext = dialer.DialContext
+	switch {
+	case !data.UnixSocket.IsNull():
+		socket := data.UnixSocket.ValueString()
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+	case !data.DialAddress.IsNull():
+		dialAddress := data.DialAddress.ValueString()
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, dialAddress)
+		}
+	}
+
+	return &http.Client{
+		Timeout:   parseDurationAttribute(data.RequestTimeout, defaultRequestTimeout, "request_timeout", diag),
+		Transport: transport,
+	}
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirCountDataSource{}
+
+func NewFhirCountDataSource() datasource.DataSource {
+	return &FhirCountDataSource{}
+}
+
+// FhirCountDataSource defines the data source that counts the resources matching a search.
+type FhirCountDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirCountDataSourceModel describes the data source data model.
+type FhirCountDataSourceModel struct {
+	ResourceType     types.String `tfsdk:"resource_type"`
+	SearchParameters types.Map    `tfsdk:"search_parameters"`
+	FhirBaseUrl      types.String `tfsdk:"fhir_base_url"`
+	Headers          types.Map    `tfsdk:"headers"`
+	Strict           types.Bool   `tfsdk:"strict"`
+
+	// state
+	Total types.Int64 `tfsdk:"total"`
+}
+
+func (d *FhirCountDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_count"
+}
+
+func (d *FhirCountDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source counts the resources matching a search using `_summary=count`, without transferring the resources",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the resources to count, example Patient",
+				Required:            true,
+			},
+			"search_parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters, example `{ \"active\" = \"true\" }`. The names and values are url encoded by the provider",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"strict": schema.BoolAttribute{
+				MarkdownDescription: "Sends `Prefer: handling=strict`, so the server rejects unknown or unsupported search parameters instead of ignoring them. Issues reported by the server in the search result also fail the data source",
+				Optional:            true,
+			},
+			"total": schema.Int64Attribute{
+				MarkdownDescription: "The amount of resources matching the search",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirCountDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirCountDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirCountDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	searchParameters := make(map[string]string)
+	resp.Diagnostics.Append(data.SearchParameters.ElementsAs(ctx, &searchParameters, true)...)
+	searchParameters["_summary"] = "count"
+
+	ctx = strictSearchContext(ctx, data.Strict.ValueBool())
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+	body, shouldReturn := SendFhirRequest(ctx, d.providerSettings, "GET", searchUrl, nil, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+
+	bundle, err := parseBundle(body)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+		return
+	}
+	if data.Strict.ValueBool() && checkSearchOutcomes(bundle, searchUrl, &resp.Diagnostics) {
+		return
+	}
+	if bundle.Total == nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("the server did not return the total of the search %s", searchUrl), string(body))
+		return
+	}
+
+	data.Total = types.Int64Value(*bundle.Total)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
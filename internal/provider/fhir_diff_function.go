@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &FhirDiffFunction{}
+
+func NewFhirDiffFunction() function.Function {
+	return &FhirDiffFunction{}
+}
+
+// FhirDiffFunction defines the function that compares two FHIR json documents.
+type FhirDiffFunction struct{}
+
+// fhirDifference is a path holding different values in the compared documents, the values being json or nil when missing.
+type fhirDifference struct {
+	Path string  `tfsdk:"path"`
+	A    *string `tfsdk:"a"`
+	B    *string `tfsdk:"b"`
+}
+
+func (f *FhirDiffFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "fhir_diff"
+}
+
+func (f *FhirDiffFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Compares two FHIR json documents",
+		MarkdownDescription: "Compares two FHIR json documents semantically, ignoring the formatting and the order of the object members, and returns the differing paths with the json of their values in each document, null when missing. Paths are dot separated with numbers as array indexes, like in `set_path`, and arrays of different lengths are reported as a whole. An empty list means the documents are equal",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "a",
+				MarkdownDescription: "The first FHIR json document, example the content of a resource file",
+			},
+			function.StringParameter{
+				Name:                "b",
+				MarkdownDescription: "The second FHIR json document, example the `response_body` of a resource",
+			},
+			function.ListParameter{
+				Name:                "ignore_fields",
+				ElementType:         types.StringType,
+				AllowNullValue:      true,
+				MarkdownDescription: "The dot separated fields left out of the comparison, example `[\"meta\", \"text\"]` for the metadata and the narrative",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{
+				AttrTypes: map[string]attr.Type{
+					"path": types.StringType,
+					"a":    types.StringType,
+					"b":    types.StringType,
+				},
+			},
+		},
+	}
+}
+
+func (f *FhirDiffFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var a, b string
+	var ignoreFields []string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &a, &b, &ignoreFields))
+	if resp.Error != nil {
+		return
+	}
+
+	aValue, err := decodeJsonNumbers([]byte(a))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+	bValue, err := decodeJsonNumbers([]byte(b))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+	for _, field := range ignoreFields {
+		removeField(aValue, strings.Split(field, "."))
+		removeField(bValue, strings.Split(field, "."))
+	}
+
+	differences, err := jsonDiff(nil, aValue, bValue)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, differences))
+}
+
+// jsonDiff returns the paths under path where the decoded json values differ. Object members are compared one by one
+// in sorted order and arrays of the same length item by item, other changes are reported at the path itself.
+func jsonDiff(path []string, a interface{}, b interface{}) ([]fhirDifference, error) {
+	differences := make([]fhirDifference, 0)
+	if reflect.DeepEqual(a, b) {
+		return differences, nil
+	}
+	switch aValue := a.(type) {
+	case map[string]interface{}:
+		bValue, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(aValue)+len(bValue))
+		for key := range aValue {
+			keys = append(keys, key)
+		}
+		for key := range bValue {
+			if _, exists := aValue[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			memberPath := append(path[:len(path):len(path)], key)
+			aMember, aExists := aValue[key]
+			bMember, bExists := bValue[key]
+			if !aExists || !bExists {
+				difference, err := newFhirDifference(memberPath, aMember, aExists, bMember, bExists)
+				if err != nil {
+					return nil, err
+				}
+				differences = append(differences, difference)
+				continue
+			}
+			memberDifferences, err := jsonDiff(memberPath, aMember, bMember)
+			if err != nil {
+				return nil, err
+			}
+			differences = append(differences, memberDifferences...)
+		}
+		return differences, nil
+	case []interface{}:
+		bValue, ok := b.([]interface{})
+		if !ok || len(aValue) != len(bValue) {
+			break
+		}
+		for i := range aValue {
+			itemDifferences, err := jsonDiff(append(path[:len(path):len(path)], strconv.Itoa(i)), aValue[i], bValue[i])
+			if err != nil {
+				return nil, err
+			}
+			differences = append(differences, itemDifferences...)
+		}
+		return differences, nil
+	}
+	difference, err := newFhirDifference(path, a, true, b, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(differences, difference), nil
+}
+
+func newFhirDifference(path []string, a interface{}, aExists bool, b interface{}, bExists bool) (fhirDifference, error) {
+	difference := fhirDifference{Path: strings.Join(path, ".")}
+	var err error
+	if difference.A, err = differenceValue(a, aExists); err != nil {
+		return difference, err
+	}
+	difference.B, err = differenceValue(b, bExists)
+	return difference, err
+}
+
+// differenceValue returns the json of a compared value, or nil when it is missing.
+func differenceValue(value interface{}, exists bool) (*string, error) {
+	if !exists {
+		return nil, nil
+	}
+	encoded, err := encodeJson(value)
+	if err != nil {
+		return nil, err
+	}
+	valueJson := string(encoded)
+	return &valueJson, nil
+}
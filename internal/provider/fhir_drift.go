@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultIgnoreFields are the server managed elements changing on every write, ignored when comparing and hashing resources.
+var defaultIgnoreFields = []string{"meta.versionId", "meta.lastUpdated", "meta.source"}
+
+// jsonContains tells if actual holds every value of expected, ignoring the members only present in actual objects,
+// like the id, meta and text elements populated by the server. Arrays must have the same length.
+func jsonContains(expected interface{}, actual interface{}) bool {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range expectedValue {
+			if !jsonContains(value, actualValue[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(expectedValue) {
+			return false
+		}
+		for i := range expectedValue {
+			if !jsonContains(expectedValue[i], actualValue[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return expected == actual
+	}
+}
+
+// resourceMatchesFile tells if the resource returned by the server still holds the content of the file (or the inline content),
+// after the substitutions and with the managed tag of the provider. Files that can not be read or parsed anymore are considered
+// in sync, leaving the error to the next apply.
+func resourceMatchesFile(settings FhirResourceSettings, providerSettings *ProviderSettings, body []byte) bool {
+	fileContent := []byte(settings.Content)
+	if settings.Content == "" {
+		var err error
+		if fileContent, err = os.ReadFile(settings.FhirResourceFilePath); err != nil {
+			return true
+		}
+	}
+	var expected interface{}
+	if err := json.Unmarshal(withManagedTag(replaceValues(fileContent, settings.Substitutions), providerSettings), &expected); err != nil {
+		return true
+	}
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return true
+	}
+	for _, field := range settings.IgnoreFields {
+		removeField(expected, strings.Split(field, "."))
+		removeField(actual, strings.Split(field, "."))
+	}
+	return jsonContains(expected, actual)
+}
+
+// alteredElements lists the elements of the submitted resource the server changed or dropped when storing it, as JSON
+// pointers, example "dropped /telecom/1". The ignored fields are left out.
+func alteredElements(submitted []byte, stored []byte, ignoreFields []string) []string {
+	patch, _, err := buildPatch(updateMethodJsonPatch, stored, submitted, ignoreFields)
+	if err != nil || patch == nil {
+		return nil
+	}
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil
+	}
+	altered := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		change := "changed"
+		if operation.Op == "add" {
+			change = "dropped"
+		}
+		altered = append(altered, fmt.Sprintf("%s %s", change, operation.Path))
+	}
+	return altered
+}
+
+// importedContent returns the resource returned by the server without the ignored fields, as the inline content of an imported resource.
+func importedContent(body []byte, ignoreFields []string) string {
+	var resource interface{}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return string(body)
+	}
+	for _, field := range ignoreFields {
+		removeField(resource, strings.Split(field, "."))
+	}
+	content, err := json.Marshal(resource)
+	if err != nil {
+		return string(body)
+	}
+	return string(content)
+}
+
+// resourceHash returns the sha256 of a resource returned by the server, leaving out the ignored fields so the hash
+// only changes when the content of the resource changes. Bodies that are not json are hashed as is.
+func resourceHash(body []byte, ignoreFields []string) string {
+	var resource interface{}
+	if len(ignoreFields) > 0 && json.Unmarshal(body, &resource) == nil {
+		for _, field := range ignoreFields {
+			removeField(resource, strings.Split(field, "."))
+		}
+		if normalized, err := json.Marshal(resource); err == nil {
+			body = normalized
+		}
+	}
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:])
+}
+
+// removeField deletes the element at the dot separated path, example meta.versionId, from a decoded json value.
+// Arrays along the path are traversed, removing the element from each item.
+func removeField(value interface{}, path []string) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(typed, path[0])
+			return
+		}
+		removeField(typed[path[0]], path[1:])
+	case []interface{}:
+		for _, item := range typed {
+			removeField(item, path)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofhir/fhirpath"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FhirPathPatch{}
+var _ resource.ResourceWithModifyPlan = &FhirPathPatch{}
+var _ resource.ResourceWithMoveState = &FhirPathPatch{}
+
+func NewFhirPathPatch() resource.Resource {
+	return &FhirPathPatch{}
+}
+
+// FhirPathPatch defines the resource that applies FHIRPath Patch operations to a resource managed outside of terraform.
+type FhirPathPatch struct {
+	providerSettings *ProviderSettings
+}
+
+type FhirPathPatchModel struct {
+	// from model
+	ResourceId  types.String                  `tfsdk:"resource_id"`
+	Operations  []FhirPathPatchOperationModel `tfsdk:"operations"`
+	FhirBaseUrl types.String                  `tfsdk:"fhir_base_url"`
+	Headers     types.Map                     `tfsdk:"headers"`
+
+	//actual state
+	VersionId types.String `tfsdk:"version_id"`
+	InSync    types.Bool   `tfsdk:"in_sync"`
+}
+
+// FhirPathPatchOperationModel describes an operation of the FHIRPath Patch.
+type FhirPathPatchOperationModel struct {
+	Type        types.String `tfsdk:"type"`
+	Path        types.String `tfsdk:"path"`
+	Name        types.String `tfsdk:"name"`
+	Value       types.String `tfsdk:"value"`
+	ValueType   types.String `tfsdk:"value_type"`
+	Index       types.Int64  `tfsdk:"index"`
+	Source      types.Int64  `tfsdk:"source"`
+	Destination types.Int64  `tfsdk:"destination"`
+}
+
+func (r *FhirPathPatch) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhirpath_patch"
+}
+
+func (r *FhirPathPatch) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Applies FHIRPath Patch operations to an existing resource that is not managed by terraform. On refresh the elements targeted by the operations are compared with the server, and the operations that drifted are applied again. The operations are not reversed when destroyed. A `fhirrest_fhir_resource` can be moved to it with a `moved` block, to stop managing the whole resource without deleting it, the operations being applied by the next apply",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the resource to patch, example Organization/08146022-932a-4001-9fe4-928382855ddf",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"operations": schema.ListNestedAttribute{
+				MarkdownDescription: "The operations of the patch, applied in order",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "The type of the operation, one of `add`, `insert`, `delete`, `replace` or `move`",
+							Required:            true,
+							Validators:          []validator.String{stringvalidator.OneOf("add", "insert", "delete", "replace", "move")},
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "The FHIRPath of the element the operation applies to, example `Organization.active`",
+							Required:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the element to add, used by `add`",
+							Optional:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "The value as json string, example `jsonencode(false)` or `jsonencode({ system = \"http://example.com\", value = \"123\" })`, used by `add`, `insert` and `replace`",
+							Optional:            true,
+						},
+						"value_type": schema.StringAttribute{
+							MarkdownDescription: "The FHIR type of a primitive value, example `Code` sends `valueCode`. When not set the type is guessed from the json value: `String`, `Boolean`, `Integer` or `Decimal`",
+							Optional:            true,
+						},
+						"index": schema.Int64Attribute{
+							MarkdownDescription: "The position to insert the value at, used by `insert`",
+							Optional:            true,
+						},
+						"source": schema.Int64Attribute{
+							MarkdownDescription: "The position of the item to move, used by `move`",
+							Optional:            true,
+						},
+						"destination": schema.Int64Attribute{
+							MarkdownDescription: "The position to move the item to, used by `move`",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this resource, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "The meta.versionId of the patched resource",
+				Computed:            true,
+			},
+			"in_sync": schema.BoolAttribute{
+				MarkdownDescription: "Whether the elements targeted by the operations still hold the patched values. When they drift, the next apply sends the drifted operations again. `move` operations are not checked",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *FhirPathPatch) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	r.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (r *FhirPathPatch) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FhirPathPatchModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	if r.applyOperations(ctx, &data, data.Operations, &resp.Diagnostics) {
+		return
+	}
+	data.InSync = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPathPatch) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FhirPathPatchModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer()), data.ResourceId.ValueString())
+	response, err := DoFhirRequest(ctx, r.providerSettings, "GET", url, nil)
+	if err == nil && isDeletedResponse(response) {
+		tflog.Warn(ctx, fmt.Sprintf("the patched resource %s was not found on the server (%s), removing the patch from the state", data.ResourceId.ValueString(), response.Status))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if checkFhirResponse("GET", url, response, err, &resp.Diagnostics) {
+		return
+	}
+
+	drifted := driftedFhirPathPatchOperations(ctx, response.Body, data.Operations)
+	data.InSync = types.BoolValue(len(drifted) == 0)
+	data.VersionId = types.StringNull()
+	if versionId := resourceVersionId(response.Body); versionId != "" {
+		data.VersionId = types.StringValue(versionId)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPathPatch) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FhirPathPatchModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	body, shouldReturn := ReadFhirResource(ctx, r.providerSettings, data.FhirBaseUrl.ValueStringPointer(), data.ResourceId.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	// Only the operations that do not hold anymore are sent, adding the same element twice would duplicate it.
+	drifted := driftedFhirPathPatchOperations(ctx, body, data.Operations)
+	data.VersionId = types.StringNull()
+	if versionId := resourceVersionId(body); versionId != "" {
+		data.VersionId = types.StringValue(versionId)
+	}
+	if len(drifted) > 0 && r.applyOperations(ctx, &data, drifted, &resp.Diagnostics) {
+		return
+	}
+	data.InSync = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPathPatch) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// The operations are not reversed, the resource only leaves the state.
+}
+
+func (r *FhirPathPatch) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: moveStateFromFhirResource,
+		},
+	}
+}
+
+func (r *FhirPathPatch) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on create and destroy.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var inSync types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("in_sync"), &inSync)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Planning the patch back in sync triggers an update sending the drifted operations again.
+	if !inSync.IsNull() && !inSync.ValueBool() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("in_sync"), true)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version_id"), types.StringUnknown())...)
+	}
+}
+
+// applyOperations sends the operations as a FHIRPath Patch Parameters resource, storing the version of the patched resource.
+func (r *FhirPathPatch) applyOperations(ctx context.Context, data *FhirPathPatchModel, operations []FhirPathPatchOperationModel, diag *diag.Diagnostics) bool {
+	parameters := make([]interface{}, 0, len(operations))
+	for _, operation := range operations {
+		parameter, shouldReturn := fhirPathPatchParameter(operation, diag)
+		if shouldReturn {
+			return true
+		}
+		parameters = append(parameters, parameter)
+	}
+	body, err := json.Marshal(map[string]interface{}{"resourceType": "Parameters", "parameter": parameters})
+	if err != nil {
+		diag.AddError(fmt.Sprintf("failed to marshal the FHIRPath Patch of the resource %s", data.ResourceId.ValueString()), err.Error())
+		return true
+	}
+
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer()), data.ResourceId.ValueString())
+	ctx = withRequestHeaders(ctx, map[string]string{"Content-Type": fhirJsonContentType})
+	responseBody, shouldReturn := SendFhirRequest(ctx, r.providerSettings, "PATCH", url, body, diag)
+	if shouldReturn {
+		return true
+	}
+	data.VersionId = types.StringNull()
+	if versionId := resourceVersionId(responseBody); versionId != "" {
+		data.VersionId = types.StringValue(versionId)
+	}
+	return false
+}
+
+// fhirPathPatchParameter returns the operation parameter of the FHIRPath Patch Parameters resource.
+func fhirPathPatchParameter(operation FhirPathPatchOperationModel, diag *diag.Diagnostics) (map[string]interface{}, bool) {
+	parts := []interface{}{
+		map[string]interface{}{"name": "type", "valueCode": operation.Type.ValueString()},
+		map[string]interface{}{"name": "path", "valueString": operation.Path.ValueString()},
+	}
+	if !operation.Name.IsNull() {
+		parts = append(parts, map[string]interface{}{"name": "name", "valueString": operation.Name.ValueString()})
+	}
+	if !operation.Index.IsNull() {
+		parts = append(parts, map[string]interface{}{"name": "index", "valueInteger": operation.Index.ValueInt64()})
+	}
+	if !operation.Source.IsNull() {
+		parts = append(parts, map[string]interface{}{"name": "source", "valueInteger": operation.Source.ValueInt64()})
+	}
+	if !operation.Destination.IsNull() {
+		parts = append(parts, map[string]interface{}{"name": "destination", "valueInteger": operation.Destination.ValueInt64()})
+	}
+	if !operation.Value.IsNull() {
+		var value interface{}
+		if err := json.Unmarshal([]byte(operation.Value.ValueString()), &value); err != nil {
+			diag.AddError(fmt.Sprintf("the value of the %s operation on %s is not valid json", operation.Type.ValueString(), operation.Path.ValueString()), err.Error())
+			return nil, true
+		}
+		if valueType := operation.ValueType.ValueString(); valueType != "" && !isJsonContainer(value) {
+			parts = append(parts, map[string]interface{}{"name": "value", "value" + valueType: value})
+		} else {
+			parts = append(parts, fhirPathPatchParts("value", value)...)
+		}
+	}
+	return map[string]interface{}{"name": "operation", "part": parts}, false
+}
+
+// driftedFhirPathPatchOperations returns the operations whose targeted elements do not hold the patched values on the resource.
+// Operations that can not be checked, like move, are considered in sync.
+func driftedFhirPathPatchOperations(ctx context.Context, body []byte, operations []FhirPathPatchOperationModel) []FhirPathPatchOperationModel {
+	drifted := make([]FhirPathPatchOperationModel, 0)
+	for _, operation := range operations {
+		inSync, err := fhirPathPatchOperationInSync(body, operation)
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("could not check the %s operation on %s: %s", operation.Type.ValueString(), operation.Path.ValueString(), err.Error()))
+			continue
+		}
+		if !inSync {
+			drifted = append(drifted, operation)
+		}
+	}
+	return drifted
+}
+
+// fhirPathPatchOperationInSync tells if the elements targeted by the operation hold the patched value.
+func fhirPathPatchOperationInSync(body []byte, operation FhirPathPatchOperationModel) (bool, error) {
+	expression := operation.Path.ValueString()
+	if operation.Type.ValueString() == "add" {
+		expression = fmt.Sprintf("%s.%s", expression, operation.Name.ValueString())
+	}
+	var expected interface{}
+	if !operation.Value.IsNull() {
+		if err := json.Unmarshal([]byte(operation.Value.ValueString()), &expected); err != nil {
+			return false, err
+		}
+	}
+
+	switch operation.Type.ValueString() {
+	case "delete":
+		result, err := fhirpath.Evaluate(body, expression)
+		if err != nil {
+			return false, err
+		}
+		return result.Count() == 0, nil
+	case "add", "insert", "replace":
+		result, err := fhirpath.Evaluate(body, expression)
+		if err != nil {
+			return false, err
+		}
+		for _, item := range result {
+			var actual interface{}
+			if err := json.Unmarshal(fhirPathValueToJson(item), &actual); err != nil {
+				continue
+			}
+			if jsonContains(expected, actual) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// isJsonContainer tells if a decoded json value is an object or an array.
+func isJsonContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
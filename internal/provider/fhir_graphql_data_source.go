@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirGraphqlDataSource{}
+
+func NewFhirGraphqlDataSource() datasource.DataSource {
+	return &FhirGraphqlDataSource{}
+}
+
+// FhirGraphqlDataSource defines the data source that runs a query on the $graphql endpoint.
+type FhirGraphqlDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirGraphqlDataSourceModel describes the data source data model.
+type FhirGraphqlDataSourceModel struct {
+	Query       types.String `tfsdk:"query"`
+	ResourceId  types.String `tfsdk:"resource_id"`
+	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+
+	// state
+	Result types.String `tfsdk:"result"`
+}
+
+func (d *FhirGraphqlDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_graphql"
+}
+
+func (d *FhirGraphqlDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source submits a GraphQL query to the $graphql endpoint of the fhir server and returns the result as a json",
+
+		Attributes: map[string]schema.Attribute{
+			"query": schema.StringAttribute{
+				MarkdownDescription: "The GraphQL query, example `{ PatientList(name: \"smith\") { id name { given family } } }`",
+				Required:            true,
+			},
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "When set the query runs on the instance level endpoint of this resource, example Patient/08146022-932a-4001-9fe4-928382855ddf",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"result": schema.StringAttribute{
+				MarkdownDescription: "The json returned by the server as string",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirGraphqlDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirGraphqlDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirGraphqlDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	url := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	if data.ResourceId.ValueString() != "" {
+		url = fmt.Sprintf("%s/%s", url, data.ResourceId.ValueString())
+	}
+	url = fmt.Sprintf("%s/$graphql", url)
+
+	requestBody, _ := json.Marshal(map[string]string{"query": data.Query.ValueString()})
+	body, shouldReturn := SendFhirRequest(ctx, d.providerSettings, "POST", url, requestBody, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+
+	var responseJson map[string]interface{}
+	if err := json.Unmarshal(body, &responseJson); err != nil {
+		resp.Diagnostics.AddError("failed to unmarshal the GraphQL response", err.Error())
+		return
+	}
+	if errors, ok := responseJson["errors"]; ok {
+		errorsJson, _ := json.Marshal(errors)
+		resp.Diagnostics.AddError(fmt.Sprintf("the GraphQL query on the url %s returned errors", url), string(errorsJson))
+		return
+	}
+
+	data.Result = d.providerSettings.stateBody(body)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
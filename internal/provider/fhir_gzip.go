@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+// minGzipBodySize is the smallest request body compressed when compression is enabled, smaller bodies are not worth it.
+const minGzipBodySize = 1024
+
+// gzipBody compresses a request body with gzip.
+func gzipBody(body []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// responseBodyReader returns a reader of the response body, decompressing it when the server sent it gzipped.
+func responseBodyReader(response *http.Response) (io.Reader, error) {
+	if response.Header.Get("Content-Encoding") != "gzip" || response.Uncompressed {
+		return response.Body, nil
+	}
+	return gzip.NewReader(response.Body)
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// FhirHapiModel describes the hapi provider attribute.
+type FhirHapiModel struct {
+	CacheControl            types.String `tfsdk:"cache_control"`
+	ExpungeOnDelete         types.Bool   `tfsdk:"expunge_on_delete"`
+	ReindexSearchParameters types.Bool   `tfsdk:"reindex_search_parameters"`
+}
+
+// HapiSettings enables the behaviors specific to the HAPI FHIR JPA server.
+type HapiSettings struct {
+	// CacheControl is sent with the read and search requests, empty when no header is sent.
+	CacheControl            string
+	ExpungeOnDelete         bool
+	ReindexSearchParameters bool
+}
+
+func hapiSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Enables the helpers specific to the HAPI FHIR JPA server. Other HAPI operations, like `$meta` or `$expunge` on a whole type, can be invoked with the `fhirrest_operation` action",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"cache_control": schema.StringAttribute{
+				MarkdownDescription: "The Cache-Control header sent with the read and search requests, example `no-cache` for searches to bypass the search result cache of HAPI and see the resources written in the same apply",
+				Optional:            true,
+			},
+			"expunge_on_delete": schema.BoolAttribute{
+				MarkdownDescription: "After deleting a `fhirrest_fhir_resource`, sends `$expunge` with `expungeDeletedResources` and `expungePreviousVersions`, removing the resource and its history from the database so it can be created again with the same unique values. Requires the expunge operations to be enabled on the server",
+				Optional:            true,
+			},
+			"reindex_search_parameters": schema.BoolAttribute{
+				MarkdownDescription: "After creating or updating a SearchParameter with `fhirrest_fhir_resource`, sends `$mark-all-resources-for-reindexing` for the base types of the search parameter, so the existing resources are searchable with it",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newHapiSettings builds the HAPI settings from the provider configuration.
+func newHapiSettings(data *FhirHapiModel) HapiSettings {
+	if data == nil {
+		return HapiSettings{}
+	}
+	return HapiSettings{
+		CacheControl:            data.CacheControl.ValueString(),
+		ExpungeOnDelete:         data.ExpungeOnDelete.ValueBool(),
+		ReindexSearchParameters: data.ReindexSearchParameters.ValueBool(),
+	}
+}
+
+// expungeResource removes a deleted resource and its history from the HAPI database. A failure is reported as a warning,
+// as the resource was already deleted.
+func expungeResource(ctx context.Context, providerSettings *ProviderSettings, baseUrl string, resourceId string, diag *diag.Diagnostics) {
+	url := fmt.Sprintf("%s/%s/$expunge", baseUrl, resourceId)
+	body, _ := json.Marshal(map[string]interface{}{
+		"resourceType": "Parameters",
+		"parameter": []interface{}{
+			map[string]interface{}{"name": "expungeDeletedResources", "valueBoolean": true},
+			map[string]interface{}{"name": "expungePreviousVersions", "valueBoolean": true},
+		},
+	})
+	response, err := DoFhirRequest(ctx, providerSettings, "POST", url, body)
+	if err != nil {
+		diag.AddWarning(fmt.Sprintf("the resource %s was deleted but could not be expunged", resourceId), err.Error())
+		return
+	}
+	if response.Status[0] != '2' {
+		diag.AddWarning(fmt.Sprintf("the resource %s was deleted but could not be expunged, the server answered %s", resourceId, response.Status), string(response.Body))
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("expunged the resource %s", resourceId))
+}
+
+// reindexSearchParameter marks the resources of the base types of a SearchParameter for reindexing. A failure is reported as
+// a warning, as the SearchParameter was already written.
+func reindexSearchParameter(ctx context.Context, providerSettings *ProviderSettings, baseUrl string, searchParameter map[string]interface{}, diag *diag.Diagnostics) {
+	bases, _ := searchParameter["base"].([]interface{})
+	parameter := make([]interface{}, 0, len(bases))
+	for _, base := range bases {
+		parameter = append(parameter, map[string]interface{}{"name": "type", "valueString": base})
+	}
+	if len(parameter) == 0 {
+		return
+	}
+	body, _ := json.Marshal(map[string]interface{}{"resourceType": "Parameters", "parameter": parameter})
+	url := fmt.Sprintf("%s/$mark-all-resources-for-reindexing", baseUrl)
+	response, err := DoFhirRequest(ctx, providerSettings, "POST", url, body)
+	if err != nil {
+		diag.AddWarning(fmt.Sprintf("the SearchParameter %v was written but the resources could not be marked for reindexing", searchParameter["id"]), err.Error())
+		return
+	}
+	if response.Status[0] != '2' {
+		diag.AddWarning(fmt.Sprintf("the SearchParameter %v was written but the resources could not be marked for reindexing, the server answered %s", searchParameter["id"], response.Status), string(response.Body))
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("marked the %v resources for reindexing after the change of the SearchParameter %v", bases, searchParameter["id"]))
+}
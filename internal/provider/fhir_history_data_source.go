@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirHistoryDataSource{}
+
+func NewFhirHistoryDataSource() datasource.DataSource {
+	return &FhirHistoryDataSource{}
+}
+
+// FhirHistoryDataSource defines the data source that reads the version history of a resource.
+type FhirHistoryDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirHistoryDataSourceModel describes the data source data model.
+type FhirHistoryDataSourceModel struct {
+	ResourceId  types.String `tfsdk:"resource_id"`
+	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+	MaxResults  types.Int64  `tfsdk:"max_results"`
+
+	// state
+	Versions types.List `tfsdk:"versions"`
+}
+
+// FhirHistoryVersionModel describes a version of the history.
+type FhirHistoryVersionModel struct {
+	VersionId   types.String `tfsdk:"version_id"`
+	LastUpdated types.String `tfsdk:"last_updated"`
+	Method      types.String `tfsdk:"method"`
+	Resource    types.String `tfsdk:"resource"`
+}
+
+var fhirHistoryVersionAttrTypes = map[string]attr.Type{
+	"version_id":   types.StringType,
+	"last_updated": types.StringType,
+	"method":       types.StringType,
+	"resource":     types.StringType,
+}
+
+func (d *FhirHistoryDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_resource_history"
+}
+
+func (d *FhirHistoryDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source reads the version history (`_history`) of a fhir resource",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the fhir resource, example Medication/08146022-932a-4001-9fe4-928382855ddf",
+				Required:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The data source fails when the history has more versions than this. Defaults to %d", defaultMaxResults),
+				Optional:            true,
+			},
+			"versions": schema.ListNestedAttribute{
+				MarkdownDescription: "The versions of the resource, as ordered by the server (usually the newest first)",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"version_id": schema.StringAttribute{
+							MarkdownDescription: "The version id",
+							Computed:            true,
+						},
+						"last_updated": schema.StringAttribute{
+							MarkdownDescription: "When the version was created",
+							Computed:            true,
+						},
+						"method": schema.StringAttribute{
+							MarkdownDescription: "The http method that created the version, example PUT or DELETE",
+							Computed:            true,
+						},
+						"resource": schema.StringAttribute{
+							MarkdownDescription: "The resource of the version as json string. Not set for deletions",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *FhirHistoryDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirHistoryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirHistoryDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	maxResults := int64(defaultMaxResults)
+	if !data.MaxResults.IsNull() {
+		maxResults = data.MaxResults.ValueInt64()
+	}
+
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	historyUrl := fmt.Sprintf("%s/%s/_history", baseUrl, data.ResourceId.ValueString())
+	versions := []FhirHistoryVersionModel{}
+	shouldReturn := searchAllPages(ctx, d.providerSettings, historyUrl, maxResults, &resp.Diagnostics, func(bundle *FhirBundle) {
+		for _, entry := range bundle.Entry {
+			versions = append(versions, historyVersion(entry, d.providerSettings))
+		}
+	})
+	if shouldReturn {
+		return
+	}
+
+	versionsList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: fhirHistoryVersionAttrTypes}, versions)
+	resp.Diagnostics.Append(diags...)
+	data.Versions = versionsList
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// historyVersion reads a history entry, taking the version from the resource meta or, for deletions, from the etag.
+func historyVersion(entry FhirBundleEntry, providerSettings *ProviderSettings) FhirHistoryVersionModel {
+	version := FhirHistoryVersionModel{
+		VersionId:   types.StringNull(),
+		LastUpdated: types.StringNull(),
+		Method:      types.StringNull(),
+		Resource:    types.StringNull(),
+	}
+	if entry.Request.Method != "" {
+		version.Method = types.StringValue(entry.Request.Method)
+	}
+	if etag := strings.Trim(strings.TrimPrefix(entry.Response.Etag, "W/"), "\""); etag != "" {
+		version.VersionId = types.StringValue(etag)
+	}
+	if entry.Response.LastModified != "" {
+		version.LastUpdated = types.StringValue(entry.Response.LastModified)
+	}
+	if len(entry.Resource) == 0 {
+		return version
+	}
+
+	version.Resource = providerSettings.stateBody(entry.Resource)
+	var resource struct {
+		Meta struct {
+			VersionId   string `json:"versionId"`
+			LastUpdated string `json:"lastUpdated"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(entry.Resource, &resource); err == nil {
+		if resource.Meta.VersionId != "" {
+			version.VersionId = types.StringValue(resource.Meta.VersionId)
+		}
+		if resource.Meta.LastUpdated != "" {
+			version.LastUpdated = types.StringValue(resource.Meta.LastUpdated)
+		}
+	}
+	return version
+}
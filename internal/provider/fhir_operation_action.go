@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ action.Action = &FhirOperationAction{}
+var _ action.ActionWithConfigure = &FhirOperationAction{}
+
+func NewFhirOperationAction() action.Action {
+	return &FhirOperationAction{}
+}
+
+// FhirOperationAction defines the action invoking a FHIR operation.
+type FhirOperationAction struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirOperationActionModel describes the action data model.
+type FhirOperationActionModel struct {
+	Operation   types.String `tfsdk:"operation"`
+	Method      types.String `tfsdk:"method"`
+	Parameters  types.Map    `tfsdk:"parameters"`
+	Body        types.String `tfsdk:"body"`
+	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+}
+
+func (a *FhirOperationAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operation"
+}
+
+func (a *FhirOperationAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Invokes a FHIR operation, like `$reindex`, `$expunge` or a cache invalidation, when triggered by a lifecycle event or by `terraform apply -invoke`. Requires Terraform 1.14 or later",
+
+		Attributes: map[string]schema.Attribute{
+			"operation": schema.StringAttribute{
+				MarkdownDescription: "The path of the operation relative to the base url, at the system, type or instance level, example `$reindex`, `Patient/$expunge` or `Patient/123/$everything`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"method": schema.StringAttribute{
+				MarkdownDescription: "The http method, `POST` or `GET` for the operations without side effects. Defaults to `POST`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive("POST", "GET"),
+				},
+			},
+			"parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The string parameters of the operation, sent as a Parameters resource with `POST` or in the query string with `GET`, example `{ \"expungeDeletedResources\" = \"true\" }`",
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.ConflictsWith(path.MatchRoot("body")),
+				},
+			},
+			"body": schema.StringAttribute{
+				MarkdownDescription: "The body sent with `POST`, usually a Parameters resource as json string, for parameters which are not strings",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the request of this action, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (a *FhirOperationAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	a.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (a *FhirOperationAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data FhirOperationActionModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	parameters := make(map[string]string)
+	resp.Diagnostics.Append(data.Parameters.ElementsAs(ctx, &parameters, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	method := "POST"
+	if data.Method.ValueString() != "" {
+		method = strings.ToUpper(data.Method.ValueString())
+	}
+	baseUrl := resolveBaseUrl(a.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	url := fmt.Sprintf("%s/%s", baseUrl, strings.TrimPrefix(data.Operation.ValueString(), "/"))
+
+	var requestBody []byte
+	switch {
+	case method == "GET":
+		if len(parameters) > 0 {
+			url = fmt.Sprintf("%s?%s", url, encodeSearchParameters(parameters))
+		}
+	case !data.Body.IsNull():
+		requestBody = []byte(data.Body.ValueString())
+	default:
+		requestBody = operationParameters(parameters)
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{Message: fmt.Sprintf("invoking %s %s", method, url)})
+	response, shouldReturn := SendFhirRequestWithResponse(ctx, a.providerSettings, method, url, requestBody, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	resp.SendProgress(action.InvokeProgressEvent{Message: fmt.Sprintf("the operation %s answered %s", data.Operation.ValueString(), response.Status)})
+}
+
+// operationParameters returns the Parameters resource holding the string parameters of an operation, sorted by name.
+func operationParameters(parameters map[string]string) []byte {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parameter := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		parameter = append(parameter, map[string]interface{}{"name": name, "valueString": parameters[name]})
+	}
+	body, _ := json.Marshal(map[string]interface{}{"resourceType": "Parameters", "parameter": parameter})
+	return body
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// FhirOperationOutcome holds the issues of an OperationOutcome returned by the server.
+type FhirOperationOutcome struct {
+	ResourceType string `json:"resourceType"`
+	Issue        []struct {
+		Severity    string `json:"severity"`
+		Code        string `json:"code"`
+		Diagnostics string `json:"diagnostics"`
+		Details     struct {
+			Text string `json:"text"`
+		} `json:"details"`
+		Expression []string `json:"expression"`
+	} `json:"issue"`
+}
+
+// parseOperationOutcome returns the OperationOutcome held by body, or nil when body is not an OperationOutcome.
+func parseOperationOutcome(body []byte) *FhirOperationOutcome {
+	var outcome FhirOperationOutcome
+	if err := json.Unmarshal(body, &outcome); err != nil || outcome.ResourceType != "OperationOutcome" {
+		return nil
+	}
+	return &outcome
+}
+
+// Messages returns a readable message for each issue with one of the given severities.
+func (o *FhirOperationOutcome) Messages(severities ...string) []string {
+	messages := make([]string, 0, len(o.Issue))
+	for _, issue := range o.Issue {
+		if len(severities) > 0 && !slices.Contains(severities, issue.Severity) {
+			continue
+		}
+		text := issue.Diagnostics
+		if text == "" {
+			text = issue.Details.Text
+		}
+		message := fmt.Sprintf("%s (%s): %s", issue.Severity, issue.Code, text)
+		if len(issue.Expression) > 0 {
+			message += fmt.Sprintf(" at %s", strings.Join(issue.Expression, ", "))
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// reportOutcomeWarnings adds the information and warning issues of an OperationOutcome returned with a successful response as
+// a warning, like the validation hints of servers answering with Prefer: return=OperationOutcome.
+func reportOutcomeWarnings(method string, url string, body []byte, diag *diag.Diagnostics) {
+	outcome := parseOperationOutcome(body)
+	if outcome == nil {
+		return
+	}
+	if messages := outcome.Messages("information", "warning"); len(messages) > 0 {
+		diag.AddWarning(fmt.Sprintf("the server reported issues on the %s request on the url %s", method, url), strings.Join(messages, "\n"))
+	}
+}
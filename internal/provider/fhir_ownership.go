@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// fhirCoding is a Coding element, like the tags of meta.tag.
+type fhirCoding struct {
+	System string `json:"system"`
+	Code   string `json:"code"`
+}
+
+// addManagedTag adds the managed tag of the provider to the meta.tag of a resource, unless it already holds it.
+func addManagedTag(resource map[string]interface{}, providerSettings *ProviderSettings) {
+	meta, ok := resource["meta"].(map[string]interface{})
+	if !ok {
+		meta = make(map[string]interface{})
+		resource["meta"] = meta
+	}
+	tags, _ := meta["tag"].([]interface{})
+	for _, tag := range tags {
+		coding, ok := tag.(map[string]interface{})
+		if ok && coding["system"] == providerSettings.ManagedTagSystem && coding["code"] == providerSettings.ManagedTagCode {
+			return
+		}
+	}
+	meta["tag"] = append(tags, map[string]interface{}{"system": providerSettings.ManagedTagSystem, "code": providerSettings.ManagedTagCode})
+}
+
+// withManagedTag returns the content of a resource as it is sent to the server, with the managed tag of the provider when
+// it tags the resources. Contents that are not json objects are returned as is.
+func withManagedTag(content []byte, providerSettings *ProviderSettings) []byte {
+	if providerSettings == nil || providerSettings.ManagedTagCode == "" {
+		return content
+	}
+	var resource map[string]interface{}
+	if err := json.Unmarshal(content, &resource); err != nil {
+		return content
+	}
+	addManagedTag(resource, providerSettings)
+	tagged, err := json.Marshal(resource)
+	if err != nil {
+		return content
+	}
+	return tagged
+}
+
+// hasManagedTag tells if the meta.tag of a resource holds the managed tag of the provider.
+func hasManagedTag(body []byte, providerSettings *ProviderSettings) bool {
+	var resource struct {
+		Meta struct {
+			Tag []fhirCoding `json:"tag"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return false
+	}
+	for _, tag := range resource.Meta.Tag {
+		if tag.System == providerSettings.ManagedTagSystem && tag.Code == providerSettings.ManagedTagCode {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOwnership reads the resource and fails when it lacks the managed tag of the provider, protecting resources authored
+// outside of terraform from being overwritten or deleted. Resources that do not exist anymore pass the check.
+func checkOwnership(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, diag *diag.Diagnostics) bool {
+	if providerSettings.ManagedTagCode == "" {
+		return false
+	}
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(providerSettings, resourceBaseUrl), resourceId)
+	response, err := DoFhirRequest(ctx, providerSettings, "GET", url, nil)
+	if err == nil && isDeletedResponse(response) {
+		return false
+	}
+	if checkFhirResponse("GET", url, response, err, diag) {
+		return true
+	}
+	if !hasManagedTag(response.Body, providerSettings) {
+		diag.AddError(
+			fmt.Sprintf("the resource %s is not managed by terraform", resourceId),
+			fmt.Sprintf("The resource lacks the tag %s|%s set by the provider on every write, it may have been authored outside of terraform. Set ignore_ownership to change it anyway", providerSettings.ManagedTagSystem, providerSettings.ManagedTagCode),
+		)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,417 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	updateMethodPut           = "put"
+	updateMethodJsonPatch     = "json-patch"
+	updateMethodFhirPathPatch = "fhirpath-patch"
+
+	jsonPatchContentType = "application/json-patch+json"
+	// fhirJsonContentType is the Content-Type of the FHIRPath Patch requests, sending a Parameters resource.
+	fhirJsonContentType = "application/fhir+json"
+)
+
+// jsonPatchOperation is an operation of a RFC 6902 JSON Patch.
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// buildPatch returns the body and the Content-Type of the PATCH request bringing the elements of the file into the current
+// resource, or a nil body when the resource already holds them. Elements only present on the server are left untouched,
+// as the drift detection does.
+func buildPatch(updateMethod string, currentBody []byte, desiredBody []byte, ignoreFields []string) ([]byte, string, error) {
+	var current, desired map[string]interface{}
+	if err := json.Unmarshal(currentBody, &current); err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal(desiredBody, &desired); err != nil {
+		return nil, "", err
+	}
+	for _, field := range ignoreFields {
+		removeField(current, strings.Split(field, "."))
+		removeField(desired, strings.Split(field, "."))
+	}
+	resourceType := fmt.Sprintf("%s", desired["resourceType"])
+
+	keys := make([]string, 0, len(desired))
+	for key, value := range desired {
+		if key == "resourceType" || key == "id" || jsonContains(value, current[key]) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, "", nil
+	}
+	sort.Strings(keys)
+
+	switch updateMethod {
+	case updateMethodJsonPatch:
+		operations := make([]jsonPatchOperation, 0, len(keys))
+		for _, key := range keys {
+			if _, ok := current[key]; !ok {
+				operations = append(operations, jsonPatchOperation{Op: "add", Path: "/" + jsonPointerEscape(key), Value: desired[key]})
+				continue
+			}
+			operations = append(operations, jsonPatchDiff("/"+jsonPointerEscape(key), current[key], desired[key])...)
+		}
+		body, err := json.Marshal(operations)
+		return body, jsonPatchContentType, err
+	case updateMethodFhirPathPatch:
+		operations := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			operations = append(operations, fhirPathPatchElement(resourceType, key, current[key], desired[key])...)
+		}
+		body, err := json.Marshal(map[string]interface{}{"resourceType": "Parameters", "parameter": operations})
+		return body, fhirJsonContentType, err
+	}
+	return nil, "", fmt.Errorf("unsupported update method %s", updateMethod)
+}
+
+// jsonPatchDiff returns the minimal JSON Patch operations bringing the desired value into the current one at the pointer.
+// Object members are compared one by one and arrays of the same length item by item, other changes replace the value.
+func jsonPatchDiff(pointer string, current interface{}, desired interface{}) []jsonPatchOperation {
+	if jsonContains(desired, current) {
+		return nil
+	}
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		currentValue, ok := current.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(desiredValue))
+		for key := range desiredValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		operations := make([]jsonPatchOperation, 0)
+		for _, key := range keys {
+			memberPointer := pointer + "/" + jsonPointerEscape(key)
+			if _, exists := currentValue[key]; !exists {
+				operations = append(operations, jsonPatchOperation{Op: "add", Path: memberPointer, Value: desiredValue[key]})
+				continue
+			}
+			operations = append(operations, jsonPatchDiff(memberPointer, currentValue[key], desiredValue[key])...)
+		}
+		return operations
+	case []interface{}:
+		currentValue, ok := current.([]interface{})
+		if !ok || len(currentValue) != len(desiredValue) {
+			break
+		}
+		operations := make([]jsonPatchOperation, 0)
+		for i := range desiredValue {
+			operations = append(operations, jsonPatchDiff(fmt.Sprintf("%s/%d", pointer, i), currentValue[i], desiredValue[i])...)
+		}
+		return operations
+	}
+	return []jsonPatchOperation{{Op: "replace", Path: pointer, Value: desired}}
+}
+
+// fhirPathPatchElement returns the FHIRPath Patch operations setting the element name of the resource to the desired value.
+// Repeating elements are deleted item by item and added again, as the operations only target single elements.
+func fhirPathPatchElement(resourceType string, name string, current interface{}, desired interface{}) []interface{} {
+	elementPath := fmt.Sprintf("%s.%s", resourceType, name)
+	desiredItems, isList := desired.([]interface{})
+	if !isList {
+		if current == nil {
+			return []interface{}{fhirPathPatchOperation("add", resourceType, name, desired)}
+		}
+		return []interface{}{fhirPathPatchOperation("replace", elementPath, "", desired)}
+	}
+
+	operations := make([]interface{}, 0)
+	if currentItems, ok := current.([]interface{}); ok {
+		for i := len(currentItems) - 1; i >= 0; i-- {
+			operations = append(operations, fhirPathPatchOperation("delete", fmt.Sprintf("%s[%d]", elementPath, i), "", nil))
+		}
+	} else if current != nil {
+		operations = append(operations, fhirPathPatchOperation("delete", elementPath, "", nil))
+	}
+	for _, item := range desiredItems {
+		operations = append(operations, fhirPathPatchOperation("add", resourceType, name, item))
+	}
+	return operations
+}
+
+// fhirPathPatchOperation returns an operation parameter of a FHIRPath Patch. The name is only used by add operations
+// and the value is left out of delete operations.
+func fhirPathPatchOperation(operationType string, path string, name string, value interface{}) map[string]interface{} {
+	parts := []interface{}{
+		map[string]interface{}{"name": "type", "valueCode": operationType},
+		map[string]interface{}{"name": "path", "valueString": path},
+	}
+	if name != "" {
+		parts = append(parts, map[string]interface{}{"name": "name", "valueString": name})
+	}
+	if value != nil {
+		parts = append(parts, fhirPathPatchParts("value", value)...)
+	}
+	return map[string]interface{}{"name": "operation", "part": parts}
+}
+
+// fhirPathPatchParts encodes a json value as parameter parts: primitives become a value[x] guessed from the json type,
+// objects nested parts named after their members and arrays one part per item. The FHIR type of the elements is not known,
+// so strings are always sent as valueString, even for code, uri or date elements.
+func fhirPathPatchParts(name string, value interface{}) []interface{} {
+	switch typed := value.(type) {
+	case []interface{}:
+		parts := make([]interface{}, 0, len(typed))
+		for _, item := range typed {
+			parts = append(parts, fhirPathPatchParts(name, item)...)
+		}
+		return parts
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fhirPathPatchParts(key, typed[key])...)
+		}
+		return []interface{}{map[string]interface{}{"name": name, "part": parts}}
+	case nil:
+		return nil
+	case bool:
+		return []interface{}{map[string]interface{}{"name": name, "valueBoolean": typed}}
+	case float64:
+		if typed == math.Trunc(typed) {
+			return []interface{}{map[string]interface{}{"name": name, "valueInteger": int64(typed)}}
+		}
+		return []interface{}{map[string]interface{}{"name": name, "valueDecimal": typed}}
+	default:
+		return []interface{}{map[string]interface{}{"name": name, "valueString": fmt.Sprintf("%v", typed)}}
+	}
+}
+
+// jsonPointerEscape escapes a member name for a RFC 6901 JSON Pointer.
+func jsonPointerEscape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// applyJsonPatch applies the operations of a JSON Patch to a decoded json document, returning the patched document and
+// the operations restoring the original one. Only the add, remove, replace and test operations are supported.
+func applyJsonPatch(document interface{}, operations []jsonPatchOperation) (interface{}, []jsonPatchOperation, error) {
+	reverse := make([]jsonPatchOperation, 0, len(operations))
+	for _, operation := range operations {
+		if !strings.HasPrefix(operation.Path, "/") {
+			return nil, nil, fmt.Errorf("invalid path %q of the %s operation, it must start with /", operation.Path, operation.Op)
+		}
+		tokens := jsonPointerTokens(operation.Path)
+		patched, inverse, err := applyJsonPatchOperation(document, tokens, "", operation)
+		if err != nil {
+			return nil, nil, err
+		}
+		document = patched
+		if inverse != nil {
+			reverse = append(reverse, *inverse)
+		}
+	}
+	slices.Reverse(reverse)
+	return document, reverse, nil
+}
+
+// applyJsonPatchOperation applies an operation at the location of the tokens within value, returning the new value and the
+// operation restoring the previous one. The prefix is the pointer of value within the document.
+func applyJsonPatchOperation(value interface{}, tokens []string, prefix string, operation jsonPatchOperation) (interface{}, *jsonPatchOperation, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("the %s operation can not target the whole resource", operation.Op)
+	}
+	key := tokens[0]
+	pointer := prefix + "/" + jsonPointerEscape(key)
+
+	switch container := value.(type) {
+	case map[string]interface{}:
+		current, exists := container[key]
+		if len(tokens) > 1 {
+			if !exists {
+				return nil, nil, fmt.Errorf("the path %s does not exist", pointer)
+			}
+			patched, inverse, err := applyJsonPatchOperation(current, tokens[1:], pointer, operation)
+			if err != nil {
+				return nil, nil, err
+			}
+			container[key] = patched
+			return container, inverse, nil
+		}
+		switch operation.Op {
+		case "add":
+			container[key] = operation.Value
+			if exists {
+				return container, &jsonPatchOperation{Op: "replace", Path: pointer, Value: current}, nil
+			}
+			return container, &jsonPatchOperation{Op: "remove", Path: pointer}, nil
+		case "remove":
+			if !exists {
+				return nil, nil, fmt.Errorf("the path %s does not exist", pointer)
+			}
+			delete(container, key)
+			return container, &jsonPatchOperation{Op: "add", Path: pointer, Value: current}, nil
+		case "replace":
+			if !exists {
+				return nil, nil, fmt.Errorf("the path %s does not exist", pointer)
+			}
+			container[key] = operation.Value
+			return container, &jsonPatchOperation{Op: "replace", Path: pointer, Value: current}, nil
+		case "test":
+			if !exists || !reflect.DeepEqual(current, operation.Value) {
+				return nil, nil, fmt.Errorf("the test of the path %s failed", pointer)
+			}
+			return container, nil, nil
+		}
+	case []interface{}:
+		index := len(container)
+		if key != "-" {
+			parsed, err := strconv.Atoi(key)
+			if err != nil || parsed < 0 || parsed > len(container) {
+				return nil, nil, fmt.Errorf("the path %s does not exist", pointer)
+			}
+			index = parsed
+		}
+		// Only additions can target the end of the array.
+		if index == len(container) && (operation.Op != "add" || len(tokens) > 1) {
+			return nil, nil, fmt.Errorf("the path %s does not exist", pointer)
+		}
+		pointer = fmt.Sprintf("%s/%d", prefix, index)
+		if len(tokens) > 1 {
+			patched, inverse, err := applyJsonPatchOperation(container[index], tokens[1:], pointer, operation)
+			if err != nil {
+				return nil, nil, err
+			}
+			container[index] = patched
+			return container, inverse, nil
+		}
+		switch operation.Op {
+		case "add":
+			return slices.Insert(container, index, operation.Value), &jsonPatchOperation{Op: "remove", Path: pointer}, nil
+		case "remove":
+			current := container[index]
+			return slices.Delete(container, index, index+1), &jsonPatchOperation{Op: "add", Path: pointer, Value: current}, nil
+		case "replace":
+			current := container[index]
+			container[index] = operation.Value
+			return container, &jsonPatchOperation{Op: "replace", Path: pointer, Value: current}, nil
+		case "test":
+			if !reflect.DeepEqual(container[index], operation.Value) {
+				return nil, nil, fmt.Errorf("the test of the path %s failed", pointer)
+			}
+			return container, nil, nil
+		}
+	default:
+		return nil, nil, fmt.Errorf("the path %s does not exist", pointer)
+	}
+	return nil, nil, fmt.Errorf("unsupported JSON Patch operation %s, only add, remove, replace and test are supported", operation.Op)
+}
+
+// driftedJsonPatchOperations returns the operations of a JSON Patch whose targets do not hold the patched values on the
+// resource. Operations that can not be checked, like the test operations and the removals of array items, are considered
+// in sync.
+func driftedJsonPatchOperations(body []byte, operations []jsonPatchOperation) ([]jsonPatchOperation, error) {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+	drifted := make([]jsonPatchOperation, 0)
+	for _, operation := range operations {
+		if !jsonPatchOperationInSync(document, operation) {
+			drifted = append(drifted, operation)
+		}
+	}
+	return drifted, nil
+}
+
+// jsonPatchOperationInSync tells if the document holds the result of the operation. Additions to arrays are in sync when
+// the array holds the value at any position, as applying them again would add the value twice.
+func jsonPatchOperationInSync(document interface{}, operation jsonPatchOperation) bool {
+	tokens := jsonPointerTokens(operation.Path)
+	if operation.Op == "test" || len(tokens) == 0 {
+		return true
+	}
+	parent, exists := resolveJsonPointer(document, tokens[:len(tokens)-1])
+	if !exists {
+		return operation.Op == "remove"
+	}
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		current, exists := container[last]
+		if operation.Op == "remove" {
+			return !exists
+		}
+		return exists && jsonContains(operation.Value, current)
+	case []interface{}:
+		switch operation.Op {
+		case "add":
+			return slices.ContainsFunc(container, func(item interface{}) bool { return jsonContains(operation.Value, item) })
+		case "replace":
+			index, err := strconv.Atoi(last)
+			return err == nil && index >= 0 && index < len(container) && jsonContains(operation.Value, container[index])
+		}
+		return true
+	}
+	return operation.Op == "remove"
+}
+
+// jsonPointerTokens returns the unescaped reference tokens of a RFC 6901 JSON Pointer.
+func jsonPointerTokens(pointer string) []string {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil
+	}
+	tokens := strings.Split(pointer, "/")[1:]
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// resolveJsonPointer returns the value at the location of the tokens within the document, and false when it does not exist.
+func resolveJsonPointer(document interface{}, tokens []string) (interface{}, bool) {
+	value := document
+	for _, token := range tokens {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			member, exists := container[token]
+			if !exists {
+				return nil, false
+			}
+			value = member
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, false
+			}
+			value = container[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// mergeReversePatch adds to a reverse JSON Patch the operations of another one targeting paths it does not restore yet,
+// so the values patched first keep being restored to their original value.
+func mergeReversePatch(reverse []jsonPatchOperation, added []jsonPatchOperation) []jsonPatchOperation {
+	merged := make([]jsonPatchOperation, 0, len(reverse)+len(added))
+	for _, operation := range added {
+		if !slices.ContainsFunc(reverse, func(existing jsonPatchOperation) bool { return existing.Path == operation.Path }) {
+			merged = append(merged, operation)
+		}
+	}
+	// The added operations were applied last, they are reversed first.
+	return append(merged, reverse...)
+}
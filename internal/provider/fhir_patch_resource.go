@@ -0,0 +1,374 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FhirPatch{}
+var _ resource.ResourceWithModifyPlan = &FhirPatch{}
+var _ resource.ResourceWithMoveState = &FhirPatch{}
+
+func NewFhirPatch() resource.Resource {
+	return &FhirPatch{}
+}
+
+// FhirPatch defines the resource that applies a JSON Patch to a resource managed outside of terraform.
+type FhirPatch struct {
+	providerSettings *ProviderSettings
+}
+
+type FhirPatchModel struct {
+	// from model
+	ResourceId  types.String `tfsdk:"resource_id"`
+	Patch       types.String `tfsdk:"patch"`
+	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+
+	//actual state
+	ReversePatch types.String `tfsdk:"reverse_patch"`
+	VersionId    types.String `tfsdk:"version_id"`
+	InSync       types.Bool   `tfsdk:"in_sync"`
+}
+
+func (r *FhirPatch) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_patch"
+}
+
+func (r *FhirPatch) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Applies a RFC 6902 JSON Patch to an existing resource that is not managed by terraform, for surgical edits of vendor managed resources. The patch is applied again when the resource drifts, and reversed when destroyed. A `fhirrest_fhir_resource` can be moved to it with a `moved` block, to stop managing the whole resource without deleting it, the patch being applied by the next apply",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the resource to patch, example Organization/08146022-932a-4001-9fe4-928382855ddf",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"patch": schema.StringAttribute{
+				MarkdownDescription: "The JSON Patch as json string, example `jsonencode([{ op = \"replace\", path = \"/active\", value = false }])`. Only the add, remove, replace and test operations are supported. Changing it reverses the previous patch before applying the new one",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplaceIf(
+					// A patch resource moved from a fhir_resource has no patch yet, the first one is applied in place.
+					func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+						resp.RequiresReplace = !req.StateValue.IsNull()
+					},
+					"Changing the patch reverses the previous patch before applying the new one.",
+					"Changing the patch reverses the previous patch before applying the new one.",
+				)},
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this resource, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"reverse_patch": schema.StringAttribute{
+				MarkdownDescription: "The JSON Patch restoring the values the patch changed, applied when the resource is destroyed",
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "The meta.versionId of the patched resource",
+				Computed:            true,
+			},
+			"in_sync": schema.BoolAttribute{
+				MarkdownDescription: "Whether the resource on the server still holds the changes of the patch, checked operation by operation, additions to arrays being in sync when the array holds the value at any position. When the resource drifts, the next apply sends the drifted operations again",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *FhirPatch) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	r.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (r *FhirPatch) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FhirPatchModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	operations, shouldReturn := parseJsonPatch(data.Patch.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	body, shouldReturn := ReadFhirResource(ctx, r.providerSettings, data.FhirBaseUrl.ValueStringPointer(), data.ResourceId.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	reversePatch, shouldReturn := r.applyPatch(ctx, &data, body, operations, types.StringNull(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	data.ReversePatch = types.StringValue(reversePatch)
+	data.InSync = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPatch) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FhirPatchModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer()), data.ResourceId.ValueString())
+	response, err := DoFhirRequest(ctx, r.providerSettings, "GET", url, nil)
+	if err == nil && isDeletedResponse(response) {
+		tflog.Warn(ctx, fmt.Sprintf("the patched resource %s was not found on the server (%s), removing the patch from the state", data.ResourceId.ValueString(), response.Status))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if checkFhirResponse("GET", url, response, err, &resp.Diagnostics) {
+		return
+	}
+	data.VersionId = types.StringNull()
+	if versionId := resourceVersionId(response.Body); versionId != "" {
+		data.VersionId = types.StringValue(versionId)
+	}
+	if data.Patch.IsNull() {
+		// Moved from a fhir_resource, the patch is applied by the next apply.
+		data.InSync = types.BoolValue(false)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	operations, shouldReturn := parseJsonPatch(data.Patch.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	// The resource is in sync when the target of every operation holds the patched value.
+	drifted, err := driftedJsonPatchOperations(response.Body, operations)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", data.ResourceId.ValueString()), err.Error())
+		return
+	}
+	data.InSync = types.BoolValue(len(drifted) == 0)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPatch) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state FhirPatchModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	var data FhirPatchModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	operations, shouldReturn := parseJsonPatch(data.Patch.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	body, shouldReturn := ReadFhirResource(ctx, r.providerSettings, data.FhirBaseUrl.ValueStringPointer(), data.ResourceId.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	// Only the operations that do not hold anymore are sent, adding the same array item twice would duplicate it.
+	drifted, err := driftedJsonPatchOperations(body, operations)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", data.ResourceId.ValueString()), err.Error())
+		return
+	}
+	// Nothing is sent when only the headers changed, or when the resource still holds the patch.
+	data.VersionId = types.StringNull()
+	if versionId := resourceVersionId(body); versionId != "" {
+		data.VersionId = types.StringValue(versionId)
+	}
+	if len(drifted) > 0 {
+		reversePatch, shouldReturn := r.applyPatch(ctx, &data, body, drifted, state.ReversePatch, &resp.Diagnostics)
+		if shouldReturn {
+			return
+		}
+		// A reverse patch known while planning can not change anymore.
+		if data.ReversePatch.IsUnknown() {
+			data.ReversePatch = types.StringValue(reversePatch)
+		}
+	}
+	if data.ReversePatch.IsUnknown() {
+		data.ReversePatch = state.ReversePatch
+	}
+	data.InSync = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPatch) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FhirPatchModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.ReversePatch.IsNull() {
+		// Moved from a fhir_resource and never applied, there is nothing to reverse.
+		return
+	}
+	operations, shouldReturn := parseJsonPatch(data.ReversePatch.ValueString(), &resp.Diagnostics)
+	if shouldReturn || len(operations) == 0 {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+	ctx = withRequestHeaders(ctx, map[string]string{"Content-Type": jsonPatchContentType})
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer()), data.ResourceId.ValueString())
+	response, err := DoFhirRequest(ctx, r.providerSettings, "PATCH", url, []byte(data.ReversePatch.ValueString()))
+	if err == nil && isDeletedResponse(response) {
+		tflog.Info(ctx, fmt.Sprintf("the patched resource %s was already deleted (%s), nothing to reverse", data.ResourceId.ValueString(), response.Status))
+		return
+	}
+	checkFhirResponse("PATCH", url, response, err, &resp.Diagnostics)
+}
+
+func (r *FhirPatch) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: moveStateFromFhirResource,
+		},
+	}
+}
+
+func (r *FhirPatch) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on create and destroy.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var inSync types.Bool
+	var reversePatch types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("in_sync"), &inSync)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("reverse_patch"), &reversePatch)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Planning the patch back in sync triggers an update applying it again.
+	drifted := !inSync.IsNull() && !inSync.ValueBool()
+	if drifted {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("in_sync"), true)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version_id"), types.StringUnknown())...)
+	}
+	// The operations sent again can add paths to the reverse patch, and a resource moved from a fhir_resource only gets
+	// one once the patch is first applied.
+	if drifted || reversePatch.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("reverse_patch"), types.StringUnknown())...)
+	}
+}
+
+// applyPatch checks the operations apply to the resource read from the server and sends them as a JSON Patch, returning
+// the JSON Patch reversing them. The previous reverse patch, if any, is kept for the paths it already restores, so they
+// are restored to the values before terraform first patched the resource.
+func (r *FhirPatch) applyPatch(ctx context.Context, data *FhirPatchModel, body []byte, operations []jsonPatchOperation, previousReversePatch types.String, diag *diag.Diagnostics) (string, bool) {
+	var current interface{}
+	if err := json.Unmarshal(body, &current); err != nil {
+		diag.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", data.ResourceId.ValueString()), err.Error())
+		return "", true
+	}
+	_, reverse, err := applyJsonPatch(current, operations)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("the patch does not apply to the resource %s", data.ResourceId.ValueString()), err.Error())
+		return "", true
+	}
+	if !previousReversePatch.IsNull() {
+		previous, shouldReturn := parseJsonPatch(previousReversePatch.ValueString(), diag)
+		if shouldReturn {
+			return "", true
+		}
+		reverse = mergeReversePatch(previous, reverse)
+	}
+	reversePatch, err := json.Marshal(reverse)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("failed to marshal the reverse patch of the resource %s", data.ResourceId.ValueString()), err.Error())
+		return "", true
+	}
+	patch, err := json.Marshal(operations)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("failed to marshal the patch of the resource %s", data.ResourceId.ValueString()), err.Error())
+		return "", true
+	}
+
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer()), data.ResourceId.ValueString())
+	ctx = withRequestHeaders(ctx, map[string]string{"Content-Type": jsonPatchContentType})
+	responseBody, shouldReturn := SendFhirRequest(ctx, r.providerSettings, "PATCH", url, patch, diag)
+	if shouldReturn {
+		return "", true
+	}
+	data.VersionId = types.StringNull()
+	if versionId := resourceVersionId(responseBody); versionId != "" {
+		data.VersionId = types.StringValue(versionId)
+	}
+	return string(reversePatch), false
+}
+
+// parseJsonPatch parses the operations of a JSON Patch.
+func parseJsonPatch(patch string, diag *diag.Diagnostics) ([]jsonPatchOperation, bool) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal([]byte(patch), &operations); err != nil {
+		diag.AddError("failed to parse the JSON Patch", err.Error())
+		return nil, true
+	}
+	return operations, false
+}
+
+// resourceVersionId returns the meta.versionId of a resource, or an empty string when it has none.
+func resourceVersionId(body []byte) string {
+	var resource struct {
+		Meta struct {
+			VersionId string `json:"versionId"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return ""
+	}
+	return resource.Meta.VersionId
+}
@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FhirProcessMessage{}
+
+func NewFhirProcessMessage() resource.Resource {
+	return &FhirProcessMessage{}
+}
+
+// FhirProcessMessage defines the resource that sends a message Bundle to the $process-message operation.
+type FhirProcessMessage struct {
+	providerSettings *ProviderSettings
+}
+
+type FhirProcessMessageModel struct {
+	// from model
+	FilePath      types.String `tfsdk:"file_path"`
+	FileSha256    types.String `tfsdk:"file_sha256"`
+	FhirBaseUrl   types.String `tfsdk:"fhir_base_url"`
+	Substitutions types.Map    `tfsdk:"substitutions"`
+
+	//actual state
+	Response       types.String `tfsdk:"response"`
+	ResponseSha256 types.String `tfsdk:"response_sha256"`
+}
+
+func (r *FhirProcessMessage) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_process_message"
+}
+
+func (r *FhirProcessMessage) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Sends a message Bundle to the $process-message endpoint of the FHIR server and keeps the response message in the state. Any change on the inputs sends a new message. Destroying this resource does not send anything to the server",
+
+		Attributes: map[string]schema.Attribute{
+			"file_path": schema.StringAttribute{
+				MarkdownDescription: "The path of the file containing the message Bundle",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"file_sha256": schema.StringAttribute{
+				MarkdownDescription: "The sha256 of the file. Not internally used, but useful to send the message again when the file is updated",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"substitutions": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "A map of substitutions to be applied to the file content before sending it to the server. Works the same way as in the fhirrest_fhir_resource",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Map{mapplanmodifier.RequiresReplace()},
+			},
+			"response": schema.StringAttribute{
+				MarkdownDescription: "The response message returned by the fhir server as json string",
+				Computed:            true,
+			},
+			"response_sha256": schema.StringAttribute{
+				MarkdownDescription: "The sha256 of the response of the fhir server.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *FhirProcessMessage) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	r.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (r *FhirProcessMessage) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FhirProcessMessageModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	substitutions := make(map[string]string)
+	resp.Diagnostics.Append(data.Substitutions.ElementsAs(ctx, &substitutions, true)...)
+
+	fileContent := readFileContent(data.FilePath.ValueString(), &resp.Diagnostics)
+	if fileContent == nil {
+		return
+	}
+	fileContent = replaceValues(fileContent, substitutions)
+
+	var fileContentJson map[string]interface{}
+	if err := json.Unmarshal(fileContent, &fileContentJson); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to unmarshal JSON file %s", data.FilePath.ValueString()), err.Error())
+		return
+	}
+	if fileContentJson["resourceType"] != "Bundle" || fileContentJson["type"] != "message" {
+		resp.Diagnostics.AddError(fmt.Sprintf("the file %s does not contain a message Bundle", data.FilePath.ValueString()), "The $process-message operation expects a Bundle with the type \"message\"")
+		return
+	}
+
+	url := fmt.Sprintf("%s/$process-message", resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer()))
+	body, shouldReturn := SendFhirRequest(ctx, r.providerSettings, "POST", url, fileContent, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("processed the message %s. Response: %s", data.FilePath.ValueString(), string(body)))
+
+	hash := sha256.Sum256(body)
+	data.Response = r.providerSettings.stateBody(body)
+	data.ResponseSha256 = types.StringValue(hex.EncodeToString(hash[:]))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirProcessMessage) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// A processed message can not be read back from the server, the state is kept as is.
+}
+
+func (r *FhirProcessMessage) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state FhirProcessMessageModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	var data FhirProcessMessageModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// every input requires a replacement, so nothing is sent to the server here
+	data.Response = state.Response
+	data.ResponseSha256 = state.ResponseSha256
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirProcessMessage) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Messages can not be undone, removing the resource from the state is enough.
+}
@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// purgePageSize is the amount of resources requested on each search while purging.
+const purgePageSize = "100"
+
+// maxPurgeIterations limits the searches done by a single purge, protecting against servers that keep returning resources.
+const maxPurgeIterations = 1000
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FhirPurge{}
+
+func NewFhirPurge() resource.Resource {
+	return &FhirPurge{}
+}
+
+// FhirPurge defines the resource that deletes every resource matching a search.
+type FhirPurge struct {
+	providerSettings *ProviderSettings
+}
+
+type FhirPurgeModel struct {
+	// from model
+	ResourceType     types.String `tfsdk:"resource_type"`
+	SearchParameters types.Map    `tfsdk:"search_parameters"`
+	FhirBaseUrl      types.String `tfsdk:"fhir_base_url"`
+	Triggers         types.Map    `tfsdk:"triggers"`
+	PurgeOnCreate    types.Bool   `tfsdk:"purge_on_create"`
+
+	//actual state
+	DeletedCount types.Int64 `tfsdk:"deleted_count"`
+}
+
+func (r *FhirPurge) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_purge"
+}
+
+func (r *FhirPurge) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Deletes all the resources of a type matching the search parameters when destroyed, or when the triggers change. Changing the resource type, the search parameters or the server replaces it, purging the previous selection. Intended to reset ephemeral test servers, never use it against servers holding real data",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the resources to delete, example Patient",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"search_parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters selecting the resources to delete, example `{ \"_tag\" = \"http://example.com|test-data\" }`. When not set every resource of the type is deleted",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Map{mapplanmodifier.RequiresReplace()},
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"triggers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Arbitrary values that run the purge again whenever they change",
+				Optional:            true,
+			},
+			"purge_on_create": schema.BoolAttribute{
+				MarkdownDescription: "Also runs the purge when the resource is created",
+				Optional:            true,
+			},
+			"deleted_count": schema.Int64Attribute{
+				MarkdownDescription: "The amount of resources deleted by the last purge",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *FhirPurge) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	r.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (r *FhirPurge) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FhirPurgeModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.DeletedCount = types.Int64Value(0)
+	if data.PurgeOnCreate.ValueBool() {
+		data.DeletedCount = types.Int64Value(r.purge(ctx, data, &resp.Diagnostics))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPurge) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to refresh, the purge only exists in the state.
+}
+
+func (r *FhirPurge) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state FhirPurgeModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	var data FhirPurgeModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.DeletedCount = state.DeletedCount
+	if !state.Triggers.Equal(data.Triggers) {
+		data.DeletedCount = types.Int64Value(r.purge(ctx, data, &resp.Diagnostics))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirPurge) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FhirPurgeModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.purge(ctx, data, &resp.Diagnostics)
+}
+
+// purge searches the resources again and again, deleting every match, until the search returns nothing.
+// Searching again instead of following the next links avoids skipping resources as the result set shrinks.
+func (r *FhirPurge) purge(ctx context.Context, data FhirPurgeModel, diag *diag.Diagnostics) int64 {
+	searchParameters := make(map[string]string)
+	diag.Append(data.SearchParameters.ElementsAs(ctx, &searchParameters, true)...)
+	searchParameters["_count"] = purgePageSize
+	searchParameters["_elements"] = "id"
+
+	baseUrl := resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+
+	var deleted int64
+	var previousIds []string
+	for iteration := 0; iteration < maxPurgeIterations; iteration++ {
+		body, shouldReturn := SendFhirRequest(ctx, r.providerSettings, "GET", searchUrl, nil, diag)
+		if shouldReturn {
+			return deleted
+		}
+		bundle, err := parseBundle(body)
+		if err != nil {
+			diag.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+			return deleted
+		}
+
+		ids := bundle.ResourceIds()
+		if len(ids) == 0 {
+			tflog.Info(ctx, fmt.Sprintf("purged %d resources matching %s", deleted, searchUrl))
+			return deleted
+		}
+		if slices.Equal(ids, previousIds) {
+			diag.AddError(fmt.Sprintf("the search %s keeps returning deleted resources", searchUrl), "The server still returns the resources that were just deleted, the purge was stopped to avoid an endless loop")
+			return deleted
+		}
+		previousIds = ids
+
+		for _, id := range ids {
+			url := fmt.Sprintf("%s/%s", baseUrl, id)
+			response, err := DoFhirRequest(ctx, r.providerSettings, "DELETE", url, nil)
+			if err == nil && isDeletedResponse(response) {
+				// Deleted in the meantime, by another purge or a cascade.
+				continue
+			}
+			if checkFhirResponse("DELETE", url, response, err, diag) {
+				return deleted
+			}
+			deleted++
+		}
+	}
+
+	diag.AddError(fmt.Sprintf("the purge of %s did not finish", searchUrl), fmt.Sprintf("Stopped after %d searches, %d resources were deleted", maxPurgeIterations, deleted))
+	return deleted
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirRawGetDataSource{}
+
+func NewFhirRawGetDataSource() datasource.DataSource {
+	return &FhirRawGetDataSource{}
+}
+
+// FhirRawGetDataSource defines the data source that fetches an arbitrary path of the server.
+type FhirRawGetDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirRawGetDataSourceModel describes the data source data model.
+type FhirRawGetDataSourceModel struct {
+	Path           types.String `tfsdk:"path"`
+	FhirBaseUrl    types.String `tfsdk:"fhir_base_url"`
+	RequestHeaders types.Map    `tfsdk:"request_headers"`
+
+	// state
+	StatusCode types.Int64  `tfsdk:"status_code"`
+	Headers    types.Map    `tfsdk:"headers"`
+	Body       types.String `tfsdk:"body"`
+}
+
+func (d *FhirRawGetDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_raw_get"
+}
+
+func (d *FhirRawGetDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source sends a GET request to any path relative to the base url and returns the raw response, for the cases not covered by the other data sources. Error statuses do not fail the data source, check `status_code` instead",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The path relative to the base url, including the query string, example `Patient?name=foo&_format=json`. It is sent as is, without any encoding",
+				Required:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"request_headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the request, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"status_code": schema.Int64Attribute{
+				MarkdownDescription: "The http status code of the response",
+				Computed:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The headers of the response. Headers sent several times are joined with a comma",
+				Computed:            true,
+			},
+			"body": schema.StringAttribute{
+				MarkdownDescription: "The body of the response",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirRawGetDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirRawGetDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirRawGetDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.RequestHeaders, &resp.Diagnostics)
+
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	url := fmt.Sprintf("%s/%s", baseUrl, strings.TrimPrefix(data.Path.ValueString(), "/"))
+	response, err := DoFhirRequest(ctx, d.providerSettings, "GET", url, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("could not send the GET request using the URL %s", url), err.Error())
+		return
+	}
+
+	headers := make(map[string]string, len(response.Header))
+	for name, values := range response.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	headersMap, diags := types.MapValueFrom(ctx, types.StringType, headers)
+	resp.Diagnostics.Append(diags...)
+
+	data.StatusCode = types.Int64Value(int64(response.StatusCode))
+	data.Headers = headersMap
+	data.Body = d.providerSettings.stateBody(response.Body)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
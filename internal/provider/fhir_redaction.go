@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// FhirStateRedactionModel describes the state_redaction provider attribute.
+type FhirStateRedactionModel struct {
+	Elements   types.List `tfsdk:"elements"`
+	OmitBodies types.Bool `tfsdk:"omit_bodies"`
+}
+
+// StateRedaction defines what is removed from the resource bodies before they are written to the state.
+type StateRedaction struct {
+	// Elements are the element paths removed from the resources, example Patient.name, or telecom for every resource type.
+	Elements []string
+	// OmitBodies leaves the body attributes null instead of storing the bodies.
+	OmitBodies bool
+}
+
+func stateRedactionSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Keeps PHI out of the state. Applies to the body attributes of the resources and data sources, like `response_body`, `resource`, `resources` and `bundle`. The sensitivity of an attribute is part of its schema and can not depend on the provider configuration, use `omit_bodies` for the bodies to not be written to the state at all. The configured content of the resources, including the content set on import, is written as is",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"elements": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The elements removed from the resources before they are written to the state, example `[\"Patient.name\", \"telecom\"]`. Paths starting with a resource type only apply to that type, the others to every resource, including the resources of Bundles and the contained ones",
+				Optional:            true,
+			},
+			"omit_bodies": schema.BoolAttribute{
+				MarkdownDescription: "Leaves the body attributes null, so no resource body is written to the state",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newStateRedaction builds the state redaction from the provider configuration.
+func newStateRedaction(ctx context.Context, data *FhirStateRedactionModel, diag *diag.Diagnostics) StateRedaction {
+	redaction := StateRedaction{}
+	if data == nil {
+		return redaction
+	}
+	diag.Append(data.Elements.ElementsAs(ctx, &redaction.Elements, true)...)
+	redaction.OmitBodies = data.OmitBodies.ValueBool()
+	return redaction
+}
+
+// enabled tells if anything is redacted from the bodies.
+func (r StateRedaction) enabled() bool {
+	return r.OmitBodies || len(r.Elements) > 0
+}
+
+// stateBody returns the body as it can be written to the state: null when the bodies are omitted, else without the redacted
+// elements. Bodies that are not json are kept as is.
+func (s *ProviderSettings) stateBody(body []byte) types.String {
+	if s == nil {
+		return types.StringValue(string(body))
+	}
+	if s.StateRedaction.OmitBodies {
+		return types.StringNull()
+	}
+	return types.StringValue(string(s.StateRedaction.redact(body)))
+}
+
+// redact removes the redacted elements from every resource of the body.
+func (r StateRedaction) redact(body []byte) []byte {
+	if len(r.Elements) == 0 {
+		return body
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	r.redactValue(value)
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func (r StateRedaction) redactValue(value interface{}) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if resourceType, ok := typed["resourceType"].(string); ok {
+			for _, element := range r.Elements {
+				path := strings.Split(element, ".")
+				if startsWithUpper(path[0]) {
+					if path[0] != resourceType || len(path) == 1 {
+						continue
+					}
+					path = path[1:]
+				}
+				removeField(typed, path)
+			}
+		}
+		for _, item := range typed {
+			r.redactValue(item)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			r.redactValue(item)
+		}
+	}
+}
+
+// startsWithUpper tells if the element path starts with a resource type, which are the only capitalized FHIR names.
+func startsWithUpper(name string) bool {
+	for _, char := range name {
+		return unicode.IsUpper(char)
+	}
+	return false
+}
+
+// stateBodyList works like stateBody for a list of bodies, returning a null list when the bodies are omitted.
+func (s *ProviderSettings) stateBodyList(ctx context.Context, bodies []string) (types.List, diag.Diagnostics) {
+	if s != nil && s.StateRedaction.OmitBodies {
+		return types.ListNull(types.StringType), nil
+	}
+	redacted := make([]string, 0, len(bodies))
+	for _, body := range bodies {
+		redacted = append(redacted, s.stateBody([]byte(body)).ValueString())
+	}
+	return types.ListValueFrom(ctx, types.StringType, redacted)
+}
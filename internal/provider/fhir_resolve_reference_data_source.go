@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofhir/fhirpath"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirResolveReferenceDataSource{}
+
+func NewFhirResolveReferenceDataSource() datasource.DataSource {
+	return &FhirResolveReferenceDataSource{}
+}
+
+// FhirResolveReferenceDataSource defines the data source that fetches the resource targeted by a Reference of another resource.
+type FhirResolveReferenceDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirResolveReferenceDataSourceModel describes the data source data model.
+type FhirResolveReferenceDataSourceModel struct {
+	ResourceId    types.String `tfsdk:"resource_id"`
+	ReferencePath types.String `tfsdk:"reference_path"`
+	FhirBaseUrl   types.String `tfsdk:"fhir_base_url"`
+	Headers       types.Map    `tfsdk:"headers"`
+
+	// state
+	Reference          types.String `tfsdk:"reference"`
+	Resource           types.String `tfsdk:"resource"`
+	ResolvedResourceId types.String `tfsdk:"resolved_resource_id"`
+}
+
+func (d *FhirResolveReferenceDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resolve_reference"
+}
+
+func (d *FhirResolveReferenceDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source reads a resource, finds a Reference in it using FHIRPath and returns the referenced resource. Relative, absolute and contained references are supported",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the resource holding the Reference, example Observation/08146022-932a-4001-9fe4-928382855ddf",
+				Required:            true,
+			},
+			"reference_path": schema.StringAttribute{
+				MarkdownDescription: "The FHIRPath expression selecting a single Reference, example `Observation.subject`",
+				Required:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"reference": schema.StringAttribute{
+				MarkdownDescription: "The reference found, example Patient/123 or #contained-id",
+				Computed:            true,
+			},
+			"resource": schema.StringAttribute{
+				MarkdownDescription: "The referenced resource as json string",
+				Computed:            true,
+			},
+			"resolved_resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the referenced resource, example Patient/123. Not set for contained resources",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirResolveReferenceDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirResolveReferenceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirResolveReferenceDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	body, shouldReturn := ReadFhirResource(ctx, d.providerSettings, data.FhirBaseUrl.ValueStringPointer(), data.ResourceId.ValueString(), &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+
+	reference := findReference(body, data.ReferencePath.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Reference = types.StringValue(reference)
+	data.ResolvedResourceId = types.StringNull()
+
+	if strings.HasPrefix(reference, "#") {
+		contained := findContainedResource(body, strings.TrimPrefix(reference, "#"))
+		if contained == nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("the contained resource %s was not found in %s", reference, data.ResourceId.ValueString()), "")
+			return
+		}
+		data.Resource = d.providerSettings.stateBody(contained)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	referenceUrl := reference
+	if !strings.Contains(reference, "://") {
+		referenceUrl = fmt.Sprintf("%s/%s", resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer()), reference)
+	}
+	referencedBody, shouldReturn := SendFhirRequest(ctx, d.providerSettings, "GET", referenceUrl, nil, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	var ref fhirResourceRef
+	if err := json.Unmarshal(referencedBody, &ref); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", reference), err.Error())
+		return
+	}
+	data.Resource = d.providerSettings.stateBody(referencedBody)
+	data.ResolvedResourceId = types.StringValue(fmt.Sprintf("%s/%s", ref.ResourceType, ref.Id))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// findReference evaluates the expression on the resource, accepting either a Reference element or its reference string.
+func findReference(resource []byte, expression string, diag *diag.Diagnostics) string {
+	result, err := fhirpath.Evaluate(resource, expression)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("could not evaluate the expression %s", expression), err.Error())
+		return ""
+	}
+	if result.Count() != 1 {
+		diag.AddError(fmt.Sprintf("the expression %s must select exactly one Reference, found %d", expression, result.Count()), result.String())
+		return ""
+	}
+
+	var reference string
+	value := fhirPathValueToJson(result[0])
+	if err := json.Unmarshal(value, &reference); err == nil {
+		return reference
+	}
+	var referenceElement struct {
+		Reference string `json:"reference"`
+	}
+	if err := json.Unmarshal(value, &referenceElement); err != nil || referenceElement.Reference == "" {
+		diag.AddError(fmt.Sprintf("the expression %s does not select a Reference with a reference", expression), string(value))
+		return ""
+	}
+	return referenceElement.Reference
+}
+
+func findContainedResource(resource []byte, id string) json.RawMessage {
+	var container struct {
+		Contained []json.RawMessage `json:"contained"`
+	}
+	if err := json.Unmarshal(resource, &container); err != nil {
+		return nil
+	}
+	for _, contained := range container.Contained {
+		var ref fhirResourceRef
+		if err := json.Unmarshal(contained, &ref); err == nil && ref.Id == id {
+			return contained
+		}
+	}
+	return nil
+}
@@ -12,21 +12,37 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+// defaultOperationTimeout bounds each create, read, update and delete when no timeouts are configured.
+const defaultOperationTimeout = 20 * time.Minute
+
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &FhirResource{}
 var _ resource.ResourceWithImportState = &FhirResource{}
+var _ resource.ResourceWithModifyPlan = &FhirResource{}
 
 func NewFhirResource() resource.Resource {
 	return &FhirResource{}
@@ -36,39 +52,107 @@ func NewFhirResource() resource.Resource {
 type FhirResource struct {
 	providerSettings     *ProviderSettings
 	fhirResourceSettings FhirResourceSettings
+	// the resource last seen on the server, used as current resource of the patch updates
+	baseline *fhirResourceBaseline
 }
 
 type FhirResourceSettings struct {
 	FhirResourceFilePath string
+	Content              string
+	WriteOnly            bool
 	FhirBaseUrl          *string
 	Substitutions        map[string]string
+	IdempotencyKey       bool
+	ContentType          string
+	PreferReturn         string
+	IgnoreFields         []string
+	IfNoneExist          map[string]string
+	UpdateCriteria       map[string]string
+	UpdateMethod         string
+	AdoptIfExists        bool
+	Identifier           string
+	OnIdMismatch         string
+	InjectIdOnUpdate     bool
+	CreateMethod         string
+	FailOnWarning        bool
 }
 
 type FhirResourceModel struct {
 	// from model
-	FilePath      types.String `tfsdk:"file_path"`
-	FileSha256    types.String `tfsdk:"file_sha256"`
-	FhirBaseUrl   types.String `tfsdk:"fhir_base_url"`
-	Substitutions types.Map    `tfsdk:"substitutions"`
+	FilePath           types.String      `tfsdk:"file_path"`
+	Content            types.String      `tfsdk:"content"`
+	ContentWo          types.String      `tfsdk:"content_wo"`
+	ContentWoVersion   types.Int64       `tfsdk:"content_wo_version"`
+	HeadersWo          types.Map         `tfsdk:"headers_wo"`
+	FileSha256         types.String      `tfsdk:"file_sha256"`
+	FhirBaseUrl        types.String      `tfsdk:"fhir_base_url"`
+	Headers            types.Map         `tfsdk:"headers"`
+	Substitutions      types.Map         `tfsdk:"substitutions"`
+	IdempotencyKey     types.Bool        `tfsdk:"idempotency_key"`
+	ContentType        types.String      `tfsdk:"content_type"`
+	PreferReturn       types.String      `tfsdk:"prefer_return"`
+	Tenant             types.String      `tfsdk:"tenant"`
+	Partition          types.String      `tfsdk:"partition"`
+	IgnoreFields       types.List        `tfsdk:"ignore_fields"`
+	IfNoneExist        types.Map         `tfsdk:"if_none_exist"`
+	UpdateCriteria     types.Map         `tfsdk:"update_criteria"`
+	UpdateMethod       types.String      `tfsdk:"update_method"`
+	AdoptIfExists      types.Bool        `tfsdk:"adopt_if_exists"`
+	Identifier         types.String      `tfsdk:"identifier"`
+	DeletionProtection types.Bool        `tfsdk:"deletion_protection"`
+	SkipDelete         types.Bool        `tfsdk:"skip_delete"`
+	CascadeDelete      types.Bool        `tfsdk:"cascade_delete"`
+	VerifyDelete       types.Bool        `tfsdk:"verify_delete"`
+	OnExternalDelete   types.String      `tfsdk:"on_external_delete"`
+	OnIdMismatch       types.String      `tfsdk:"on_id_mismatch"`
+	InjectIdOnUpdate   types.Bool        `tfsdk:"inject_id_on_update"`
+	CreateMethod       types.String      `tfsdk:"create_method"`
+	ValidateOnly       types.Bool        `tfsdk:"validate_only"`
+	FailOnWarning      types.Bool        `tfsdk:"fail_on_warning"`
+	IgnoreOwnership    types.Bool        `tfsdk:"ignore_ownership"`
+	NormalizeResponse  types.Bool        `tfsdk:"normalize_response_body"`
+	WaitFor            *FhirWaitForModel `tfsdk:"wait_for"`
+	Timeouts           timeouts.Value    `tfsdk:"timeouts"`
 
 	//actual state
-	ResourceId     types.String `tfsdk:"resource_id"`
-	ResponseSha256 types.String `tfsdk:"response_sha256"`
+	ResourceId        types.String `tfsdk:"resource_id"`
+	ResponseSha256    types.String `tfsdk:"response_sha256"`
+	ResponseBody      types.String `tfsdk:"response_body"`
+	VersionId         types.String `tfsdk:"version_id"`
+	LastUpdated       types.String `tfsdk:"last_updated"`
+	InSync            types.Bool   `tfsdk:"in_sync"`
+	ValidationOutcome types.String `tfsdk:"validation_outcome"`
 }
 
 func (r *FhirResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_fhir_resource"
+	// In identifier mode the id of the resource can change when it is recreated outside of terraform.
+	resp.ResourceBehavior.MutableIdentity = true
 }
 
 func (r *FhirResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: fhirResourceSchemaVersion,
 		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "This represents a fhir resource in the FHIR server",
+		MarkdownDescription: "This represents a fhir resource in the FHIR server. Subscriptions in the requested status are read again until the server activates them, failing when the server sets them to error. Resources are imported by id, example Patient/123, or by a search matching exactly one resource, example `Patient?identifier=http://example.com|123`. A `fhirrest_fhir_patch` or `fhirrest_fhirpath_patch` can be moved to it with a `moved` block, to manage the patched resource as a whole without recreating it or reversing the patch",
 
 		Attributes: map[string]schema.Attribute{
 			"file_path": schema.StringAttribute{
-				MarkdownDescription: "The path of the file containing a fhir resource",
-				Required:            true,
+				MarkdownDescription: "The path of the file containing a fhir resource. Conflicts with content and content_wo",
+				Optional:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The fhir resource as json string, instead of a file. Set from the server resource on import, so `terraform plan -generate-config-out` writes the resource inline. Written to the state as is, the state_redaction of the provider does not apply to it, use content_wo to keep PHI out of the state. Conflicts with file_path and content_wo",
+				Optional:            true,
+			},
+			"content_wo": schema.StringAttribute{
+				MarkdownDescription: "The fhir resource as json string, write-only so the content, like PHI, is never written to the state. Only sent when content_wo_version changes, and changes made outside of terraform are not detected. Requires Terraform 1.11 or later",
+				Optional:            true,
+				WriteOnly:           true,
+			},
+			"content_wo_version": schema.Int64Attribute{
+				MarkdownDescription: "The version of content_wo, to be increased to send a new content_wo to the server",
+				Optional:            true,
 			},
 			"file_sha256": schema.StringAttribute{
 				MarkdownDescription: "The sha256 of the file. Not internally used, but useful to trigger updates when the file is updated",
@@ -78,6 +162,17 @@ func (r *FhirResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
 				Optional:            true,
 			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this resource, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"headers_wo": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Write-only headers sent with the create and update requests, merged over headers, for credentials that must not be written to the state. Requires Terraform 1.11 or later",
+				Optional:            true,
+				WriteOnly:           true,
+			},
 			"resource_id": schema.StringAttribute{
 				MarkdownDescription: "The id of the resource that was saved in the fhir server",
 				Computed:            true,
@@ -86,6 +181,26 @@ func (r *FhirResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				MarkdownDescription: "The sha256 of the response of the fhir server.",
 				Computed:            true,
 			},
+			"response_body": schema.StringAttribute{
+				MarkdownDescription: "The resource returned by the server as json string, with the elements populated by the server like generated identifiers. Not set when the content is write-only",
+				Computed:            true,
+			},
+			"normalize_response_body": schema.BoolAttribute{
+				MarkdownDescription: "Removes the ignore_fields from response_body, so it only changes when the content of the resource changes",
+				Optional:            true,
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "The version of the resource on the server (meta.versionId), changing on every write",
+				Computed:            true,
+			},
+			"last_updated": schema.StringAttribute{
+				MarkdownDescription: "When the resource was last changed on the server (meta.lastUpdated)",
+				Computed:            true,
+			},
+			"in_sync": schema.BoolAttribute{
+				MarkdownDescription: "Whether the resource on the server still holds the content of the file, checked on each refresh. When it was changed outside of terraform an update is planned to restore the file content",
+				Computed:            true,
+			},
 			"substitutions": schema.MapAttribute{
 				ElementType: basetypes.StringType{},
 				MarkdownDescription: `A map of substitutions to be applied to the file content before sending it to the server.
@@ -113,6 +228,135 @@ func (r *FhirResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				}`,
 				Optional: true,
 			},
+			"idempotency_key": schema.BoolAttribute{
+				MarkdownDescription: "Sends an Idempotency-Key header on create, derived from the server, the resource type, the file path and the content, so servers supporting it do not create the resource twice when a request is retried after a network failure, including by the next apply",
+				Optional:            true,
+			},
+			"content_type": schema.StringAttribute{
+				MarkdownDescription: "The Content-Type header of the create and update requests, example `application/fhir+json; fhirVersion=4.0`. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"prefer_return": schema.StringAttribute{
+				MarkdownDescription: "The Prefer: return header of the create and update requests, one of `minimal`, `representation` or `OperationOutcome`. With `minimal` and `OperationOutcome` the resource is read again from the url of the Location header. When not set no Prefer header is sent",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf("minimal", "representation", "OperationOutcome")},
+			},
+			"if_none_exist": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters of a conditional create, sent in the If-None-Exist header, example `{ \"identifier\" = \"http://example.com|123\" }`. When a resource already matches, the server does not create a new one and its id is adopted into the state",
+				Optional:            true,
+			},
+			"update_criteria": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters of a conditional update, example `{ \"identifier\" = \"http://example.com|123\" }`. When set, creates and updates are sent as `PUT <type>?<criteria>`, so the server updates the resource matching the criteria, or creates it when none matches, whatever id it has",
+				Optional:            true,
+			},
+			"update_method": schema.StringAttribute{
+				MarkdownDescription: "How updates are sent, one of `put`, `json-patch` or `fhirpath-patch`. With the patch methods the resource is read from the server and only the elements of the file that differ are sent, preserving the elements populated by the server. `json-patch` computes the minimal patch down to the nested members and array items, `fhirpath-patch` replaces the differing top level elements, sending the primitive values as `valueString`, `valueBoolean`, `valueInteger` or `valueDecimal` from their json type, which strict servers reject for other types like code, uri or date elements. Elements removed from the file are not removed from the server. Defaults to `put`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(updateMethodPut),
+				Validators: []validator.String{
+					stringvalidator.OneOf(updateMethodPut, updateMethodJsonPatch, updateMethodFhirPathPatch),
+				},
+			},
+			"adopt_if_exists": schema.BoolAttribute{
+				MarkdownDescription: "When the create fails with `409 Conflict` or the conditional create of `if_none_exist` matches an existing resource, the existing resource is adopted into the state and updated with the content of the file, instead of failing the apply. The existing resource is searched with `if_none_exist`, or else with the first identifier of the file, or else by the id of the file",
+				Optional:            true,
+			},
+			"identifier": schema.StringAttribute{
+				MarkdownDescription: "The business identifier keying the resource, in the form `system|value`. When set, refresh searches the resource by identifier instead of reading it by id, create updates the matching resource when one already exists, and update targets whichever resource matches, so the resource stays managed across server rebuilds",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`\|.+`), "must be in the form system|value"),
+				},
+			},
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "Makes the destroy, including a replacement, fail until the attribute is removed or set to false and applied, guarding critical resources like conformance resources against accidental deletion",
+				Optional:            true,
+			},
+			"skip_delete": schema.BoolAttribute{
+				MarkdownDescription: "Destroying the resource only removes it from the state without deleting it on the server, for shared resources like base ValueSets outliving the environment managed by terraform",
+				Optional:            true,
+			},
+			"cascade_delete": schema.BoolAttribute{
+				MarkdownDescription: "Sends the delete with `_cascade=delete`, so servers supporting it, like HAPI with cascading deletes enabled, also delete the resources referencing this one instead of failing with `409 Conflict`",
+				Optional:            true,
+			},
+			"verify_delete": schema.BoolAttribute{
+				MarkdownDescription: fmt.Sprintf("After the delete, reads the resource every %s until the server answers `404` or `410`, bounded by the delete timeout, for servers deleting asynchronously. Avoids racing the deletion when the resource is created again in the same apply", defaultWaitForInterval),
+				Optional:            true,
+			},
+			"on_external_delete": schema.StringAttribute{
+				MarkdownDescription: "What to do when the refresh finds the resource deleted outside of terraform, one of `recreate` or `error`. With `recreate` the resource is removed from the state and created again by the next apply, with `error` the refresh fails so the deletion can be investigated. Defaults to `recreate`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("recreate"),
+				Validators:          []validator.String{stringvalidator.OneOf("recreate", "error")},
+			},
+			"on_id_mismatch": schema.StringAttribute{
+				MarkdownDescription: "What to do when the file holds an id differing from the id of the resource in the state, usually a file copied from another environment, one of `error` or `warn`. The update fails with `error`, with `warn` the id of the file is replaced by the id of the state. Defaults to `error`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("error"),
+				Validators:          []validator.String{stringvalidator.OneOf("error", "warn")},
+			},
+			"validate_only": schema.BoolAttribute{
+				MarkdownDescription: "Sends the content of the file to the `$validate` operation on create and update instead of persisting it, failing on the error issues and recording the outcome in validation_outcome. Nothing is written to the server, nor deleted on destroy. Changing it replaces the resource",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+			"fail_on_warning": schema.BoolAttribute{
+				MarkdownDescription: "Fails the validation of validate_only on the warning issues too, for strict profile conformance",
+				Optional:            true,
+			},
+			"validation_outcome": schema.StringAttribute{
+				MarkdownDescription: "The OperationOutcome of the validation as json string, only set with validate_only",
+				Computed:            true,
+			},
+			"create_method": schema.StringAttribute{
+				MarkdownDescription: "How resources are created, one of `post` or `put`. With `put` the resource is created with an update-as-create on the id of the file, or on a generated UUID when the file has no id, for servers only allowing it. Defaults to `post`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("post"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("post", "put"),
+				},
+			},
+			"inject_id_on_update": schema.BoolAttribute{
+				MarkdownDescription: "Sets the id of the resource in the body of the updates. Disable it for servers rejecting ids added by the client, the content of the file is then sent as is and the id of the url remains authoritative. Defaults to true",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"ignore_ownership": schema.BoolAttribute{
+				MarkdownDescription: "Updates and deletes the resource even when it lacks the managed_tag of the provider",
+				Optional:            true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "The tenant of the resource on multi-tenant servers. Overrides the value set in the provider (if any set). Changing it creates the resource in the new tenant",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"partition": schema.StringAttribute{
+				MarkdownDescription: "The partition of the resource on HAPI / Smile CDR partitioned servers. Overrides the value set in the provider (if any set). Changing it creates the resource in the new partition",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"ignore_fields": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The dot separated paths of the elements left out when comparing the resource with the file and when computing response_sha256, example `[\"meta.versionId\", \"text\"]`. Defaults to the server managed `meta.versionId`, `meta.lastUpdated` and `meta.source`",
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"wait_for": waitForBlockSchema(),
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -142,29 +386,63 @@ func (r *FhirResource) Create(ctx context.Context, req resource.CreateRequest, r
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
-	r.fhirResourceSettings = NewFhirResourceSettings(data, ctx)
+	ctx = r.prepareOperation(ctx, data, &resp.Diagnostics)
+	ctx = r.withWriteOnlyArguments(ctx, req.Config, &resp.Diagnostics)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	body, responseJson, resourceType := persistFhirResource(ctx, r, nil, &resp.Diagnostics)
-	if responseJson == nil {
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	if data.ValidateOnly.ValueBool() {
+		if r.validateOnly(ctx, &data, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		return
 	}
 
-	hash := sha256.Sum256(body)
-	hashString := hex.EncodeToString(hash[:])
+	// In identifier mode an existing resource is updated instead of creating another one.
+	existingId, shouldReturn := r.findByIdentifier(ctx, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	if existingId != "" {
+		tflog.Info(ctx, fmt.Sprintf("the resource %s matches the identifier %s, updating it", existingId, r.fhirResourceSettings.Identifier))
+	}
+	body, responseJson, resourceType := persistFhirResource(ctx, r, nilIfEmpty(existingId), "", &resp.Diagnostics)
+	if responseJson == nil {
+		return
+	}
 
 	id := responseJson["id"].(string)
 	data.ResourceId = types.StringValue(fmt.Sprintf("%s/%s", *resourceType, id))
-	data.ResponseSha256 = types.StringValue(hashString)
+	r.setResponseState(&data, body)
+	data.InSync = types.BoolValue(true)
+
+	if waitedBody := r.waitForResource(ctx, data, responseJson, &resp.Diagnostics); waitedBody != nil {
+		r.setResponseState(&data, waitedBody)
+		body = waitedBody
+	}
+	if r.keepsBaseline() {
+		setBaseline(ctx, resp.Private, body, r.fhirResourceSettings.IgnoreFields, &resp.Diagnostics)
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	r.setIdentity(ctx, data, resp.Identity, &resp.Diagnostics)
 }
 
-func persistFhirResource(ctx context.Context, fhirResource *FhirResource, resourceId *string, diag *diag.Diagnostics) ([]byte, map[string]interface{}, *string) {
-	fileContent := readFileContent(fhirResource.fhirResourceSettings.FhirResourceFilePath, diag)
+// persistFhirResource creates the resource of the file, or updates it when resourceId is set. When versionId is set the update
+// only succeeds if the resource is still at that version on the server.
+func persistFhirResource(ctx context.Context, fhirResource *FhirResource, resourceId *string, versionId string, diag *diag.Diagnostics) ([]byte, map[string]interface{}, *string) {
+	fileContent := fhirResource.fhirResourceSettings.readContent(diag)
 	if fileContent == nil {
 		return nil, nil, nil
 	}
@@ -173,62 +451,437 @@ func persistFhirResource(ctx context.Context, fhirResource *FhirResource, resour
 
 	var fileContentJson map[string]interface{}
 	if err := json.Unmarshal(fileContent, &fileContentJson); err != nil {
-		diag.AddError(fmt.Sprintf("failed to unmarshal JSON file %s", fhirResource.fhirResourceSettings.FhirResourceFilePath), err.Error())
+		diag.AddError(fmt.Sprintf("failed to unmarshal JSON file %s", fhirResource.fhirResourceSettings.source()), err.Error())
 		return nil, nil, nil
 	}
 	resourceType, ok := fileContentJson["resourceType"]
 	resourceTypeStr := fmt.Sprintf("%s", resourceType)
 	if !ok {
-		diag.AddError(fmt.Sprintf("property resourceType not found in json file %s", fhirResource.fhirResourceSettings.FhirResourceFilePath), "")
+		diag.AddError(fmt.Sprintf("property resourceType not found in json file %s", fhirResource.fhirResourceSettings.source()), "")
 		return nil, nil, nil
 	}
 
-	baseUrl := fhirResource.providerSettings.FhirBaseUrl
-	if fhirResource.fhirResourceSettings.FhirBaseUrl != nil {
-		baseUrl = *fhirResource.fhirResourceSettings.FhirBaseUrl
-	}
+	baseUrl := resolveBaseUrl(fhirResource.providerSettings, fhirResource.fhirResourceSettings.FhirBaseUrl)
 	url := fmt.Sprintf("%s/%s", baseUrl, resourceTypeStr)
 	requestBody := fileContent
+	if fhirResource.providerSettings.ManagedTagCode != "" {
+		addManagedTag(fileContentJson, fhirResource.providerSettings)
+		requestBody, _ = json.Marshal(fileContentJson)
+	}
 	requestMethod := "POST"
-	if resourceId != nil {
+	// The headers of the write request only, the resource may be read again afterwards.
+	writeHeaders := make(map[string]string)
+	if len(fhirResource.fhirResourceSettings.UpdateCriteria) > 0 {
+		// The server resolves the resource from the criteria, the id in the state is not needed.
+		url = buildSearchUrl(baseUrl, resourceTypeStr, fhirResource.fhirResourceSettings.UpdateCriteria)
+		requestMethod = "PUT"
+		if resourceId != nil && versionId != "" {
+			writeHeaders["If-Match"] = fmt.Sprintf("W/\"%s\"", versionId)
+		}
+	} else if resourceId != nil {
 		url = fmt.Sprintf("%s/%s", baseUrl, *resourceId)
 		requestMethod = "PUT"
-		parts := strings.Split(*resourceId, "/")
-		fileContentJson["id"] = parts[len(parts)-1]
+		if fhirResource.fhirResourceSettings.InjectIdOnUpdate {
+			parts := strings.Split(*resourceId, "/")
+			fileContentJson["id"] = parts[len(parts)-1]
+			requestBody, _ = json.Marshal(fileContentJson)
+		}
+		if versionId != "" {
+			writeHeaders["If-Match"] = fmt.Sprintf("W/\"%s\"", versionId)
+		}
+	} else if fhirResource.fhirResourceSettings.CreateMethod == "put" {
+		// Update-as-create, on the id of the file or else on a generated one.
+		id, _ := fileContentJson["id"].(string)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		url = fmt.Sprintf("%s/%s/%s", baseUrl, resourceTypeStr, id)
+		requestMethod = "PUT"
+		fileContentJson["id"] = id
 		requestBody, _ = json.Marshal(fileContentJson)
+	} else if fhirResource.fhirResourceSettings.IdempotencyKey {
+		// The key is derived from the target and the content, so a create sent again by the next apply after an ambiguous
+		// failure carries the same key as the first one.
+		writeHeaders["Idempotency-Key"] = idempotencyKey(baseUrl, resourceTypeStr, fhirResource.fhirResourceSettings.source(), fileContent)
 	}
-	postRequest, err := http.NewRequest(requestMethod, url, bytes.NewBuffer(requestBody))
-	if err != nil {
-		diag.AddError("failed to create new request", err.Error())
-		return nil, nil, nil
+	if resourceId == nil && len(fhirResource.fhirResourceSettings.IfNoneExist) > 0 {
+		writeHeaders["If-None-Exist"] = encodeSearchParameters(fhirResource.fhirResourceSettings.IfNoneExist)
 	}
-	for key, value := range fhirResource.providerSettings.DefaultHeaders {
-		postRequest.Header.Set(key, value)
+	if fhirResource.fhirResourceSettings.ContentType != "" {
+		writeHeaders["Content-Type"] = fhirResource.fhirResourceSettings.ContentType
 	}
-	postRequest.Header.Set("Content-Type", "application/json")
-
-	postResponse, err := fhirResource.providerSettings.Client.Do(postRequest)
-	if err != nil {
-		diag.AddError(fmt.Sprintf("could not post the %s on the url %s", resourceType, url), err.Error())
+	preferReturn := fhirResource.fhirResourceSettings.PreferReturn
+	if preferReturn != "" {
+		writeHeaders["Prefer"] = "return=" + preferReturn
+	}
+	submittedBody := requestBody
+	if updateMethod := fhirResource.fhirResourceSettings.UpdateMethod; requestMethod == "PUT" && resourceId != nil && updateMethod != "" && updateMethod != updateMethodPut {
+		// The baseline of the known version saves reading the resource again, the If-Match header rejects the patch when it is stale.
+		baseline := fhirResource.baseline
+		useBaseline := baseline != nil && versionId != "" && baseline.VersionId == versionId && writeHeaders["If-Match"] != ""
+		var currentBody []byte
+		var shouldReturn bool
+		if useBaseline {
+			currentBody = baseline.Resource
+		} else if currentBody, shouldReturn = ReadFhirResource(ctx, fhirResource.providerSettings, fhirResource.fhirResourceSettings.FhirBaseUrl, *resourceId, diag); shouldReturn {
+			return nil, nil, nil
+		}
+		patch, patchContentType, err := buildPatch(updateMethod, currentBody, requestBody, fhirResource.fhirResourceSettings.IgnoreFields)
+		if err != nil {
+			diag.AddError(fmt.Sprintf("failed to build the patch of the resource %s", *resourceId), err.Error())
+			return nil, nil, nil
+		}
+		// The baseline lacks the ignored fields, the complete resource is read when nothing is patched.
+		if patch == nil && useBaseline {
+			if currentBody, shouldReturn = ReadFhirResource(ctx, fhirResource.providerSettings, fhirResource.fhirResourceSettings.FhirBaseUrl, *resourceId, diag); shouldReturn {
+				return nil, nil, nil
+			}
+		}
+		if patch == nil {
+			tflog.Info(ctx, fmt.Sprintf("the resource %s already holds the content of the file, nothing to patch", *resourceId))
+			var currentJson map[string]interface{}
+			if err := json.Unmarshal(currentBody, &currentJson); err != nil {
+				diag.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", *resourceId), err.Error())
+				return nil, nil, nil
+			}
+			return currentBody, currentJson, &resourceTypeStr
+		}
+		requestMethod = "PATCH"
+		requestBody = patch
+		writeHeaders["Content-Type"] = patchContentType
+	}
+	response, err := DoFhirRequest(withRequestHeaders(ctx, writeHeaders), fhirResource.providerSettings, requestMethod, url, requestBody)
+	if err == nil && resourceId != nil && isVersionConflict(response, writeHeaders["If-Match"] != "") {
+		reportVersionConflict(*resourceId, versionId, response, diag)
 		return nil, nil, nil
 	}
-	defer postResponse.Body.Close()
-
-	body, _ := io.ReadAll(postResponse.Body)
-	if postResponse.Status[0] != '2' {
-		diag.AddError(fmt.Sprintf("the server returned an invalid status for the %s on the url %s: %s", resourceType, url, postResponse.Status), string(body))
+	if err == nil && resourceId == nil && fhirResource.fhirResourceSettings.AdoptIfExists && response.StatusCode == http.StatusConflict {
+		existingId := findAdoptableResource(ctx, fhirResource, baseUrl, fileContent, diag)
+		if existingId == "" {
+			return nil, nil, nil
+		}
+		diag.AddWarning(
+			fmt.Sprintf("the resource %s already existed", existingId),
+			fmt.Sprintf("The server rejected the create with %s, so the existing resource was adopted into the state and updated with the content of the file", response.Status),
+		)
+		return persistFhirResource(ctx, fhirResource, &existingId, "", diag)
+	}
+	if checkFhirResponse(requestMethod, url, response, err, diag) {
 		return nil, nil, nil
 	}
+	body := response.Body
+	// A conditional create answers 200 instead of 201 when a resource already matched the criteria.
+	adopted := resourceId == nil && len(fhirResource.fhirResourceSettings.IfNoneExist) > 0 && response.StatusCode == http.StatusOK
+
+	if preferReturn == "minimal" || preferReturn == "OperationOutcome" || len(bytes.TrimSpace(body)) == 0 || (fhirResource.providerSettings.Azure.VersionedReads && response.Header.Get("Location") != "") {
+		// The response does not hold the resource, or is not trusted, read it from the location returned by the server.
+		location := response.Header.Get("Location")
+		if location == "" {
+			location = response.Header.Get("Content-Location")
+		}
+		persistedId, persistedVersionId := parseLocation(location)
+		// A conditional update may have created a new resource, trust the location then.
+		if resourceId != nil && persistedId != *resourceId && (persistedId == "" || len(fhirResource.fhirResourceSettings.UpdateCriteria) == 0) {
+			persistedId, persistedVersionId = *resourceId, ""
+		}
+		if persistedId == "" && adopted {
+			persistedId = findExistingResource(ctx, fhirResource, baseUrl, resourceTypeStr, fhirResource.fhirResourceSettings.IfNoneExist, diag)
+			if diag.HasError() {
+				return nil, nil, nil
+			}
+		}
+		if persistedId == "" {
+			diag.AddError(fmt.Sprintf("the server did not return the Location of the created resource %s", resourceType), string(body))
+			return nil, nil, nil
+		}
+		// Read the version just written when the server tells it, a later version may already exist.
+		readId := persistedId
+		if persistedVersionId != "" {
+			readId = fmt.Sprintf("%s/_history/%s", persistedId, persistedVersionId)
+		}
+		readBody, shouldReturn := ReadFhirResource(ctx, fhirResource.providerSettings, fhirResource.fhirResourceSettings.FhirBaseUrl, readId, diag)
+		if shouldReturn {
+			return nil, nil, nil
+		}
+		body = readBody
+	}
 
 	var responseJson map[string]interface{}
 	if err := json.Unmarshal(body, &responseJson); err != nil {
 		diag.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", resourceType), err.Error())
 		return nil, nil, nil
 	}
+	if id, ok := responseJson["id"].(string); !ok || id == "" {
+		diag.AddError(fmt.Sprintf("the server did not return the id of the persisted resource %s", resourceType), string(body))
+		return nil, nil, nil
+	}
+	if adopted && fhirResource.fhirResourceSettings.AdoptIfExists {
+		existingId := fmt.Sprintf("%s/%s", resourceTypeStr, responseJson["id"])
+		diag.AddWarning(
+			fmt.Sprintf("the resource %s already existed", existingId),
+			"A resource matching if_none_exist was found on the server, so nothing was created and the existing resource was adopted into the state and updated with the content of the file",
+		)
+		return persistFhirResource(ctx, fhirResource, &existingId, "", diag)
+	}
+	if adopted {
+		diag.AddWarning(
+			fmt.Sprintf("the resource %s/%s already existed", resourceTypeStr, responseJson["id"]),
+			"A resource matching if_none_exist was found on the server, so nothing was created and the existing resource was adopted into the state",
+		)
+	}
+	if !adopted && resourceTypeStr != "Subscription" {
+		// Subscriptions are expected to change, the server manages their status.
+		if altered := alteredElements(submittedBody, body, fhirResource.fhirResourceSettings.IgnoreFields); len(altered) > 0 {
+			diag.AddWarning(
+				fmt.Sprintf("the server altered the resource %s/%s", resourceTypeStr, responseJson["id"]),
+				fmt.Sprintf("The stored resource differs from the content sent, the next refresh may plan to restore it:\n%s", strings.Join(altered, "\n")),
+			)
+		}
+	}
+	if resourceId != nil && fhirResource.providerSettings.Azure.PurgeHistory {
+		purgeHistory(ctx, fhirResource.providerSettings, resolveBaseUrl(fhirResource.providerSettings, fhirResource.fhirResourceSettings.FhirBaseUrl), fmt.Sprintf("%s/%s", resourceTypeStr, responseJson["id"]), diag)
+	}
+	if resourceTypeStr == "SearchParameter" && fhirResource.providerSettings.Hapi.ReindexSearchParameters {
+		reindexSearchParameter(ctx, fhirResource.providerSettings, resolveBaseUrl(fhirResource.providerSettings, fhirResource.fhirResourceSettings.FhirBaseUrl), responseJson, diag)
+	}
 	tflog.Debug(ctx, fmt.Sprintf("persisted the resource %s. Response: %s", resourceType, string(body)))
 	return body, responseJson, &resourceTypeStr
 }
 
+// checkIdMismatch applies on_id_mismatch when the file holds an id differing from the resource of the state, returning true
+// when the update must not be sent.
+func (r *FhirResource) checkIdMismatch(resourceId string, diag *diag.Diagnostics) bool {
+	fileContent := r.fhirResourceSettings.readContent(diag)
+	if fileContent == nil {
+		return true
+	}
+	var file fhirResourceRef
+	if err := json.Unmarshal(replaceValues(fileContent, r.fhirResourceSettings.Substitutions), &file); err != nil {
+		diag.AddError(fmt.Sprintf("failed to unmarshal JSON file %s", r.fhirResourceSettings.source()), err.Error())
+		return true
+	}
+	if file.Id == "" || resourceId == "" || fmt.Sprintf("%s/%s", file.ResourceType, file.Id) == resourceId {
+		return false
+	}
+	summary := fmt.Sprintf("the id %s of the file %s differs from the resource %s", file.Id, r.fhirResourceSettings.source(), resourceId)
+	if r.fhirResourceSettings.OnIdMismatch == "warn" {
+		diag.AddWarning(summary, fmt.Sprintf("The id of the file was replaced by the id of the resource %s", resourceId))
+		return false
+	}
+	diag.AddError(summary, "The file may have been copied from another environment. Remove the id from the file, fix it, or set on_id_mismatch to warn to update the resource of the state anyway")
+	return true
+}
+
+// handleExternalDelete applies on_external_delete to a resource deleted outside of terraform, either failing the refresh
+// or removing the resource from the state so the next apply creates it again.
+func (r *FhirResource) handleExternalDelete(ctx context.Context, data FhirResourceModel, reason string, resp *resource.ReadResponse) {
+	if data.OnExternalDelete.ValueString() == "error" {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("the resource %s was deleted outside of terraform", data.ResourceId.ValueString()),
+			fmt.Sprintf("%s. Restore it, set on_external_delete to recreate, or remove it from the state with terraform state rm", reason),
+		)
+		return
+	}
+	tflog.Warn(ctx, fmt.Sprintf("%s, removing it from the state", reason))
+	resp.State.RemoveResource(ctx)
+}
+
+// findByIdentifier searches the resource of the file matching the identifier attribute, returning its id, or an empty string
+// when none matches or the identifier is not set.
+func (r *FhirResource) findByIdentifier(ctx context.Context, diag *diag.Diagnostics) (string, bool) {
+	if r.fhirResourceSettings.Identifier == "" {
+		return "", false
+	}
+	fileContent := r.fhirResourceSettings.readContent(diag)
+	if fileContent == nil {
+		return "", true
+	}
+	var file fhirResourceRef
+	if err := json.Unmarshal(replaceValues(fileContent, r.fhirResourceSettings.Substitutions), &file); err != nil {
+		diag.AddError(fmt.Sprintf("failed to unmarshal JSON file %s", r.fhirResourceSettings.source()), err.Error())
+		return "", true
+	}
+
+	baseUrl := resolveBaseUrl(r.providerSettings, r.fhirResourceSettings.FhirBaseUrl)
+	searchUrl := buildSearchUrl(baseUrl, file.ResourceType, map[string]string{"identifier": r.fhirResourceSettings.Identifier})
+	body, shouldReturn := SendFhirRequest(ctx, r.providerSettings, "GET", searchUrl, nil, diag)
+	if shouldReturn {
+		return "", true
+	}
+	bundle, err := parseBundle(body)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+		return "", true
+	}
+	ids := bundle.ResourceIds()
+	if len(ids) > 1 {
+		diag.AddError(fmt.Sprintf("the identifier %s matches %d %s resources", r.fhirResourceSettings.Identifier, len(ids), file.ResourceType), "The identifier must match a single resource to key it")
+		return "", true
+	}
+	if len(ids) == 0 {
+		return "", false
+	}
+	return ids[0], false
+}
+
+// findAdoptableResource returns the id of the existing resource adopt_if_exists takes over, searching it with the if_none_exist
+// criteria, or else with the first identifier of the file, or else using the id of the file.
+func findAdoptableResource(ctx context.Context, fhirResource *FhirResource, baseUrl string, fileContent []byte, diag *diag.Diagnostics) string {
+	var file struct {
+		ResourceType string `json:"resourceType"`
+		Id           string `json:"id"`
+		Identifier   []struct {
+			System string `json:"system"`
+			Value  string `json:"value"`
+		} `json:"identifier"`
+	}
+	if err := json.Unmarshal(fileContent, &file); err != nil {
+		diag.AddError(fmt.Sprintf("failed to unmarshal JSON file %s", fhirResource.fhirResourceSettings.source()), err.Error())
+		return ""
+	}
+	resourceType := file.ResourceType
+	criteria := fhirResource.fhirResourceSettings.IfNoneExist
+	if len(criteria) == 0 {
+		if len(file.Identifier) > 0 && file.Identifier[0].Value != "" {
+			criteria = map[string]string{"identifier": fmt.Sprintf("%s|%s", file.Identifier[0].System, file.Identifier[0].Value)}
+		} else if file.Id != "" {
+			return fmt.Sprintf("%s/%s", resourceType, file.Id)
+		} else {
+			diag.AddError(
+				fmt.Sprintf("could not find the existing %s to adopt", resourceType),
+				"adopt_if_exists needs if_none_exist, an identifier or an id in the file to find the existing resource",
+			)
+			return ""
+		}
+	}
+	return findExistingResource(ctx, fhirResource, baseUrl, resourceType, criteria, diag)
+}
+
+// findExistingResource searches the single resource matching the criteria, used when the server answers a conditional create
+// without the resource nor its Location, and to adopt existing resources.
+func findExistingResource(ctx context.Context, fhirResource *FhirResource, baseUrl string, resourceType string, criteria map[string]string, diag *diag.Diagnostics) string {
+	searchUrl := buildSearchUrl(baseUrl, resourceType, criteria)
+	body, shouldReturn := SendFhirRequest(ctx, fhirResource.providerSettings, "GET", searchUrl, nil, diag)
+	if shouldReturn {
+		return ""
+	}
+	bundle, err := parseBundle(body)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+		return ""
+	}
+	ids := bundle.ResourceIds()
+	if len(ids) != 1 {
+		diag.AddError(fmt.Sprintf("expected exactly one %s matching the search %s, found %d", resourceType, searchUrl, len(ids)), "The existing resource can only be found when the criteria match a single resource")
+		return ""
+	}
+	return ids[0]
+}
+
+// nilIfEmpty returns a pointer to the value, or nil when the value is empty.
+func nilIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// isDeletedResponse tells if the server answered that the resource does not exist, either never existed or was deleted.
+func isDeletedResponse(response *FhirResponse) bool {
+	return response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone
+}
+
+// idempotencyKey returns the Idempotency-Key of a create, a UUID version 5 of the server, the resource type, the source of
+// the resource and the sha256 of its content.
+func idempotencyKey(baseUrl string, resourceType string, source string, content []byte) string {
+	contentHash := sha256.Sum256(content)
+	name := strings.Join([]string{baseUrl, resourceType, source, hex.EncodeToString(contentHash[:])}, "\n")
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
+
+// isVersionConflict tells if the server rejected a conditional write because the resource is not at the expected version anymore.
+// Only the requests sent with If-Match can conflict on the version, the other 409, like the referential integrity failures of
+// deletes, are reported with the OperationOutcome of the server.
+func isVersionConflict(response *FhirResponse, ifMatchSent bool) bool {
+	return ifMatchSent && (response.StatusCode == http.StatusConflict || response.StatusCode == http.StatusPreconditionFailed)
+}
+
+func reportVersionConflict(resourceId string, versionId string, response *FhirResponse, diag *diag.Diagnostics) {
+	diag.AddError(
+		fmt.Sprintf("the resource %s was modified outside of terraform", resourceId),
+		fmt.Sprintf("The server rejected the change because the resource is not at the version %s known by terraform anymore. Refresh the state and review the changes before applying again.\n\n%s", versionId, response.ErrorDetail()),
+	)
+}
+
+// keepsBaseline tells if the resource may be kept in the private state, which is part of the state file: not for write-only
+// contents nor when the provider redacts the bodies.
+func (r *FhirResource) keepsBaseline() bool {
+	return !r.fhirResourceSettings.WriteOnly && !r.providerSettings.StateRedaction.enabled()
+}
+
+// setResponseState stores the hash and the version of the resource returned by the server in the state.
+func (r *FhirResource) setResponseState(data *FhirResourceModel, body []byte) {
+	data.ResponseSha256 = types.StringValue(resourceHash(body, r.fhirResourceSettings.IgnoreFields))
+	data.ValidationOutcome = types.StringNull()
+	data.ResponseBody = types.StringNull()
+	if data.ContentWoVersion.IsNull() {
+		responseBody := body
+		if data.NormalizeResponse.ValueBool() {
+			responseBody = []byte(importedContent(body, r.fhirResourceSettings.IgnoreFields))
+		}
+		data.ResponseBody = r.providerSettings.stateBody(responseBody)
+	}
+
+	var resource struct {
+		Meta struct {
+			VersionId   string `json:"versionId"`
+			LastUpdated string `json:"lastUpdated"`
+		} `json:"meta"`
+	}
+	json.Unmarshal(body, &resource)
+	data.VersionId = types.StringNull()
+	if resource.Meta.VersionId != "" {
+		data.VersionId = types.StringValue(resource.Meta.VersionId)
+	}
+	data.LastUpdated = types.StringNull()
+	if resource.Meta.LastUpdated != "" {
+		data.LastUpdated = types.StringValue(resource.Meta.LastUpdated)
+	}
+}
+
+// waitForResource waits for the activation of Subscriptions and for the wait_for condition after a write,
+// returning the last body read from the server when any waiting happened.
+func (r *FhirResource) waitForResource(ctx context.Context, data FhirResourceModel, responseJson map[string]interface{}, diag *diag.Diagnostics) []byte {
+	var waitedBody []byte
+	status := responseJson["status"]
+	if responseJson["resourceType"] == "Subscription" && (status == "requested" || status == "error") {
+		waitedBody = waitForSubscriptionActivation(ctx, r.providerSettings, r.fhirResourceSettings.FhirBaseUrl, data.ResourceId.ValueString(), data.WaitFor, diag)
+		if diag.HasError() {
+			return nil
+		}
+	}
+	if body := waitForCondition(ctx, r.providerSettings, r.fhirResourceSettings.FhirBaseUrl, data.ResourceId.ValueString(), data.WaitFor, diag); body != nil {
+		waitedBody = body
+	}
+	return waitedBody
+}
+
+// readContent returns the inline content of the resource, or else the content of its file.
+func (s FhirResourceSettings) readContent(diag *diag.Diagnostics) []byte {
+	if s.Content != "" {
+		return []byte(s.Content)
+	}
+	return readFileContent(s.FhirResourceFilePath, diag)
+}
+
+// source names where the content of the resource comes from, for the error messages.
+func (s FhirResourceSettings) source() string {
+	if s.Content != "" {
+		return "content"
+	}
+	return s.FhirResourceFilePath
+}
+
 func readFileContent(filePath string, diag *diag.Diagnostics) []byte {
 	jsonFile, err := os.Open(filePath)
 	if err != nil {
@@ -254,13 +907,47 @@ func (r *FhirResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	setAttributeDefaults(&data)
 
-	r.fhirResourceSettings = NewFhirResourceSettings(data, ctx)
+	ctx = r.prepareOperation(ctx, data, &resp.Diagnostics)
 
-	body, shouldReturn := ReadFhirResource(r.providerSettings, r.fhirResourceSettings.FhirBaseUrl, data.ResourceId.ValueString(), &resp.Diagnostics)
-	if shouldReturn {
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	// Nothing was persisted in validate_only mode, the outcome of the last validation is kept.
+	if data.ValidateOnly.ValueBool() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	if r.fhirResourceSettings.Identifier != "" {
+		existingId, shouldReturn := r.findByIdentifier(ctx, &resp.Diagnostics)
+		if shouldReturn {
+			return
+		}
+		if existingId == "" {
+			r.handleExternalDelete(ctx, data, fmt.Sprintf("no resource matches the identifier %s anymore", r.fhirResourceSettings.Identifier), resp)
+			return
+		}
+		data.ResourceId = types.StringValue(existingId)
+	}
+
+	baseUrl := resolveBaseUrl(r.providerSettings, r.fhirResourceSettings.FhirBaseUrl)
+	url := fmt.Sprintf("%s/%s", baseUrl, data.ResourceId.ValueString())
+	response, err := DoFhirRequest(ctx, r.providerSettings, "GET", url, nil)
+	if err == nil && isDeletedResponse(response) {
+		r.handleExternalDelete(ctx, data, fmt.Sprintf("the resource %s was not found on the server (%s)", data.ResourceId.ValueString(), response.Status), resp)
+		return
+	}
+	if checkFhirResponse("GET", url, response, err, &resp.Diagnostics) {
 		return
 	}
+	body := response.Body
 
 	var responseJson map[string]interface{}
 	if err := json.Unmarshal(body, &responseJson); err != nil {
@@ -268,16 +955,38 @@ func (r *FhirResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	hash := sha256.Sum256(body)
-	hashString := hex.EncodeToString(hash[:])
-
-	id := responseJson["id"].(string)
-	resourceType := responseJson["resourceType"].(string)
+	id, idOk := responseJson["id"].(string)
+	resourceType, resourceTypeOk := responseJson["resourceType"].(string)
+	if !idOk || !resourceTypeOk || id == "" || resourceType == "" {
+		resp.Diagnostics.AddError(fmt.Sprintf("the server did not return the id and resourceType of the resource %s", data.ResourceId.ValueString()), string(body))
+		return
+	}
 	data.ResourceId = types.StringValue(fmt.Sprintf("%s/%s", resourceType, id))
-	data.ResponseSha256 = types.StringValue(hashString)
+	r.setResponseState(&data, body)
+	if !data.ContentWoVersion.IsNull() {
+		// The write-only content is not known outside of the apply, the resource is kept as is.
+		data.InSync = types.BoolValue(true)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		r.setIdentity(ctx, data, resp.Identity, &resp.Diagnostics)
+		return
+	}
+	if data.FilePath.IsNull() && data.Content.IsNull() {
+		// Imported resources take the content of the server, for the generated configuration. Like any configured content it
+		// is not redacted, a redacted content would be written to the configuration and sent back on the next update.
+		data.Content = types.StringValue(importedContent(body, r.fhirResourceSettings.IgnoreFields))
+		r.fhirResourceSettings.Content = data.Content.ValueString()
+	}
+	data.InSync = types.BoolValue(resourceMatchesFile(r.fhirResourceSettings, r.providerSettings, body))
+	if r.keepsBaseline() {
+		setBaseline(ctx, resp.Private, body, r.fhirResourceSettings.IgnoreFields, &resp.Diagnostics)
+	}
+	if !data.InSync.ValueBool() {
+		tflog.Info(ctx, fmt.Sprintf("the resource %s was changed outside of terraform, it does not match the file %s anymore", data.ResourceId.ValueString(), r.fhirResourceSettings.source()))
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	r.setIdentity(ctx, data, resp.Identity, &resp.Diagnostics)
 }
 
 func (r *FhirResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -294,25 +1003,96 @@ func (r *FhirResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	r.fhirResourceSettings = NewFhirResourceSettings(data, ctx)
+	ctx = r.prepareOperation(ctx, data, &resp.Diagnostics)
+	ctx = r.withWriteOnlyArguments(ctx, req.Config, &resp.Diagnostics)
 
-	body, responseJson, resourceType := persistFhirResource(ctx, r, state.ResourceId.ValueStringPointer(), &resp.Diagnostics)
-	if responseJson == nil {
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	if data.ValidateOnly.ValueBool() {
+		if r.validateOnly(ctx, &data, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		return
 	}
 
-	hash := sha256.Sum256(body)
-	hashString := hex.EncodeToString(hash[:])
+	if !data.IgnoreOwnership.ValueBool() && checkOwnership(ctx, r.providerSettings, r.fhirResourceSettings.FhirBaseUrl, state.ResourceId.ValueString(), &resp.Diagnostics) {
+		return
+	}
+
+	resourceId, versionId := state.ResourceId.ValueStringPointer(), state.VersionId.ValueString()
+	if r.fhirResourceSettings.Identifier != "" {
+		existingId, shouldReturn := r.findByIdentifier(ctx, &resp.Diagnostics)
+		if shouldReturn {
+			return
+		}
+		if existingId != state.ResourceId.ValueString() {
+			// Another resource holds the identifier now, the known version does not apply to it.
+			resourceId, versionId = nilIfEmpty(existingId), ""
+		}
+	}
+	if r.checkIdMismatch(state.ResourceId.ValueString(), &resp.Diagnostics) {
+		return
+	}
+	r.baseline = getBaseline(ctx, req.Private)
+	body, responseJson, resourceType := persistFhirResource(ctx, r, resourceId, versionId, &resp.Diagnostics)
+	if responseJson == nil {
+		return
+	}
 
 	id := responseJson["id"].(string)
 	state.ResourceId = types.StringValue(fmt.Sprintf("%s/%s", *resourceType, id))
-	state.ResponseSha256 = types.StringValue(hashString)
 	state.FilePath = data.FilePath
+	state.Content = data.Content
+	state.ContentWoVersion = data.ContentWoVersion
 	state.FileSha256 = data.FileSha256
 	state.Substitutions = data.Substitutions
+	state.IdempotencyKey = data.IdempotencyKey
+	state.Headers = data.Headers
+	state.ContentType = data.ContentType
+	state.PreferReturn = data.PreferReturn
+	state.Tenant = data.Tenant
+	state.Partition = data.Partition
+	state.IgnoreFields = data.IgnoreFields
+	state.IfNoneExist = data.IfNoneExist
+	state.UpdateCriteria = data.UpdateCriteria
+	state.UpdateMethod = data.UpdateMethod
+	state.AdoptIfExists = data.AdoptIfExists
+	state.Identifier = data.Identifier
+	state.DeletionProtection = data.DeletionProtection
+	state.SkipDelete = data.SkipDelete
+	state.CascadeDelete = data.CascadeDelete
+	state.VerifyDelete = data.VerifyDelete
+	state.OnExternalDelete = data.OnExternalDelete
+	state.OnIdMismatch = data.OnIdMismatch
+	state.InjectIdOnUpdate = data.InjectIdOnUpdate
+	state.CreateMethod = data.CreateMethod
+	state.ValidateOnly = data.ValidateOnly
+	state.FailOnWarning = data.FailOnWarning
+	state.IgnoreOwnership = data.IgnoreOwnership
+	state.NormalizeResponse = data.NormalizeResponse
+	state.WaitFor = data.WaitFor
+	state.Timeouts = data.Timeouts
+	state.InSync = types.BoolValue(true)
+	r.setResponseState(&state, body)
+
+	if waitedBody := r.waitForResource(ctx, state, responseJson, &resp.Diagnostics); waitedBody != nil {
+		r.setResponseState(&state, waitedBody)
+		body = waitedBody
+	}
+	if r.keepsBaseline() {
+		setBaseline(ctx, resp.Private, body, r.fhirResourceSettings.IgnoreFields, &resp.Diagnostics)
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	r.setIdentity(ctx, state, resp.Identity, &resp.Diagnostics)
 }
 
 func (r *FhirResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -325,49 +1105,234 @@ func (r *FhirResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	r.fhirResourceSettings = NewFhirResourceSettings(data, ctx)
+	if data.ValidateOnly.ValueBool() {
+		return
+	}
 
-	baseUrl := r.providerSettings.FhirBaseUrl
-	if r.fhirResourceSettings.FhirBaseUrl != nil {
-		baseUrl = *r.fhirResourceSettings.FhirBaseUrl
+	if data.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("the resource %s is protected against deletion", data.ResourceId.ValueString()),
+			"deletion_protection is set, remove it or set it to false and apply before destroying or replacing the resource",
+		)
+		return
 	}
+
+	if data.SkipDelete.ValueBool() {
+		tflog.Info(ctx, fmt.Sprintf("skip_delete is set, the resource %s is only removed from the state", data.ResourceId.ValueString()))
+		return
+	}
+
+	ctx = r.prepareOperation(ctx, data, &resp.Diagnostics)
+
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	if !data.IgnoreOwnership.ValueBool() && checkOwnership(ctx, r.providerSettings, r.fhirResourceSettings.FhirBaseUrl, data.ResourceId.ValueString(), &resp.Diagnostics) {
+		return
+	}
+	if !data.VersionId.IsNull() {
+		ctx = withRequestHeaders(ctx, map[string]string{"If-Match": fmt.Sprintf("W/\"%s\"", data.VersionId.ValueString())})
+	}
+
+	baseUrl := resolveBaseUrl(r.providerSettings, r.fhirResourceSettings.FhirBaseUrl)
 	url := fmt.Sprintf("%s/%s", baseUrl, data.ResourceId.ValueString())
-	deleteRequest, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError(fmt.Sprintf("could not create the delete request using the URL %s", url), err.Error())
+	query := make([]string, 0)
+	if data.CascadeDelete.ValueBool() {
+		query = append(query, "_cascade=delete")
+	}
+	if r.providerSettings.Azure.HardDelete {
+		query = append(query, "_hardDelete=true")
+	}
+	if len(query) > 0 {
+		url += "?" + strings.Join(query, "&")
+	}
+	response, err := DoFhirRequest(ctx, r.providerSettings, "DELETE", url, nil)
+	if err == nil && isDeletedResponse(response) {
+		tflog.Info(ctx, fmt.Sprintf("the resource %s was already deleted (%s)", data.ResourceId.ValueString(), response.Status))
 		return
 	}
-	for key, value := range r.providerSettings.DefaultHeaders {
-		deleteRequest.Header.Set(key, value)
+	if err == nil && isVersionConflict(response, !data.VersionId.IsNull()) {
+		reportVersionConflict(data.ResourceId.ValueString(), data.VersionId.ValueString(), response, &resp.Diagnostics)
+		return
 	}
-	deleteRequest.Header.Set("Content-Type", "application/json")
-	deleteResponse, err := r.providerSettings.Client.Do(deleteRequest)
-	if err != nil {
-		resp.Diagnostics.AddError(fmt.Sprintf("could not delete the resource using the URL %s", url), err.Error())
+	if checkFhirResponse("DELETE", url, response, err, &resp.Diagnostics) {
+		return
+	}
+	if data.VerifyDelete.ValueBool() {
+		waitForDeletion(ctx, r.providerSettings, r.fhirResourceSettings.FhirBaseUrl, data.ResourceId.ValueString(), &resp.Diagnostics)
+	}
+	if r.providerSettings.Hapi.ExpungeOnDelete {
+		expungeResource(ctx, r.providerSettings, baseUrl, data.ResourceId.ValueString(), &resp.Diagnostics)
+	}
+}
+
+func (r *FhirResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on create and destroy.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
 		return
 	}
 
-	defer deleteResponse.Body.Close()
+	r.planResourceId(ctx, req, resp)
+	var inSync types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("in_sync"), &inSync)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	body, _ := io.ReadAll(deleteResponse.Body)
-	if deleteResponse.Status[0] != '2' {
-		resp.Diagnostics.AddError(fmt.Sprintf("could not delete the resource using the URL %s.", url), fmt.Sprintf("Error code %s. Response: %s", deleteResponse.Status, string(body)))
+	// Planning the resource back in sync triggers an update restoring the content of the file.
+	if !inSync.IsNull() && !inSync.ValueBool() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("in_sync"), true)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("response_sha256"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("response_body"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version_id"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_updated"), types.StringUnknown())...)
 		return
 	}
+
+	r.planFromBaseline(ctx, req, resp)
+}
+
+// planResourceId keeps the id of the resource on updates, so the references to it are known while planning. In identifier
+// and update_criteria modes the update targets whichever resource matches, so the id is only known after apply.
+func (r *FhirResource) planResourceId(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var identifier types.String
+	var updateCriteria types.Map
+	var resourceId types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("identifier"), &identifier)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("update_criteria"), &updateCriteria)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("resource_id"), &resourceId)...)
+	if resp.Diagnostics.HasError() || identifier.IsUnknown() || identifier.ValueString() != "" || updateCriteria.IsUnknown() || len(updateCriteria.Elements()) > 0 {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("resource_id"), resourceId)...)
+}
+
+// planFromBaseline compares the content of the file with the baseline of the private state. The elements changed by a planned
+// update are reported as a warning, and when a patch update would send nothing the server representation is planned to stay
+// the same.
+func (r *FhirResource) planFromBaseline(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	baseline := getBaseline(ctx, req.Private)
+	if baseline == nil {
+		return
+	}
+	var plan, state FhirResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || plan.FilePath.IsUnknown() || plan.Content.IsUnknown() || plan.Substitutions.IsUnknown() {
+		return
+	}
+
+	settings := NewFhirResourceSettings(plan, ctx)
+	patch, ok := plannedPatch(settings, r.providerSettings, baseline)
+	if !ok {
+		return
+	}
+	if patch != nil {
+		// Only the changed elements are shown, the values may be sensitive.
+		tflog.Debug(ctx, fmt.Sprintf("planned changes of the resource %s: %s", state.ResourceId.ValueString(), string(patch)))
+		if !req.Plan.Raw.Equal(req.State.Raw) {
+			resp.Diagnostics.AddWarning(fmt.Sprintf("the update changes the server resource %s", state.ResourceId.ValueString()), patchPaths(patch))
+		}
+		return
+	}
+	patchUpdate := settings.UpdateMethod == updateMethodJsonPatch || settings.UpdateMethod == updateMethodFhirPathPatch
+	if patchUpdate && settings.Identifier == "" && state.VersionId.ValueString() == baseline.VersionId {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("response_sha256"), state.ResponseSha256)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("response_body"), state.ResponseBody)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version_id"), state.VersionId)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_updated"), state.LastUpdated)...)
+	}
 }
 
 func (r *FhirResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" {
+		r.importStateFromIdentity(ctx, req, resp)
+		return
+	}
+	if strings.Contains(req.ID, "?") {
+		r.importStateFromSearch(ctx, req, resp)
+		return
+	}
 	resource.ImportStatePassthroughID(ctx, path.Root("resource_id"), req, resp)
 }
 
+// importStateFromSearch imports the single resource matching a search given as import id, example Patient?identifier=http://example.com|123.
+func (r *FhirResource) importStateFromSearch(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resourceType, query, _ := strings.Cut(req.ID, "?")
+	values, err := url.ParseQuery(query)
+	if resourceType == "" || err != nil {
+		detail := "The import id must be a resource type followed by the search parameters, example Patient?identifier=http://example.com|123"
+		if err != nil {
+			detail = fmt.Sprintf("%s: %s", detail, err.Error())
+		}
+		resp.Diagnostics.AddError(fmt.Sprintf("invalid import search %s", req.ID), detail)
+		return
+	}
+
+	criteria := make(map[string]string)
+	for name := range values {
+		criteria[name] = values.Get(name)
+	}
+	baseUrl := resolveBaseUrl(r.providerSettings, nil)
+	resourceId := findExistingResource(ctx, r, baseUrl, resourceType, criteria, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("the search %s matches the resource %s, importing it", req.ID, resourceId))
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("resource_id"), resourceId)...)
+}
+
+// prepareOperation loads the settings of the resource and applies its headers, tenant and partition to the requests of the operation.
+func (r *FhirResource) prepareOperation(ctx context.Context, data FhirResourceModel, diag *diag.Diagnostics) context.Context {
+	r.fhirResourceSettings = NewFhirResourceSettings(data, ctx)
+	if !data.Tenant.IsNull() {
+		r.providerSettings = r.providerSettings.withTenant(data.Tenant.ValueString())
+	}
+	if !data.Partition.IsNull() {
+		r.providerSettings = r.providerSettings.withPartition(data.Partition.ValueString())
+	}
+	return withHeadersAttribute(ctx, data.Headers, diag)
+}
+
 func NewFhirResourceSettings(data FhirResourceModel, ctx context.Context) FhirResourceSettings {
 	substitutions := make(map[string]string)
 	data.Substitutions.ElementsAs(ctx, &substitutions, true)
 
+	ifNoneExist := make(map[string]string)
+	data.IfNoneExist.ElementsAs(ctx, &ifNoneExist, true)
+
+	updateCriteria := make(map[string]string)
+	data.UpdateCriteria.ElementsAs(ctx, &updateCriteria, true)
+
+	ignoreFields := defaultIgnoreFields
+	if !data.IgnoreFields.IsNull() {
+		ignoreFields = make([]string, 0)
+		data.IgnoreFields.ElementsAs(ctx, &ignoreFields, true)
+	}
+
 	return FhirResourceSettings{
 		FhirResourceFilePath: data.FilePath.ValueString(),
+		Content:              data.Content.ValueString(),
 		FhirBaseUrl:          data.FhirBaseUrl.ValueStringPointer(),
 		Substitutions:        substitutions,
+		IdempotencyKey:       data.IdempotencyKey.ValueBool(),
+		ContentType:          data.ContentType.ValueString(),
+		PreferReturn:         data.PreferReturn.ValueString(),
+		IgnoreFields:         ignoreFields,
+		IfNoneExist:          ifNoneExist,
+		UpdateCriteria:       updateCriteria,
+		UpdateMethod:         data.UpdateMethod.ValueString(),
+		AdoptIfExists:        data.AdoptIfExists.ValueBool(),
+		Identifier:           data.Identifier.ValueString(),
+		OnIdMismatch:         data.OnIdMismatch.ValueString(),
+		InjectIdOnUpdate:     data.InjectIdOnUpdate.IsNull() || data.InjectIdOnUpdate.ValueBool(),
+		CreateMethod:         data.CreateMethod.ValueString(),
+		FailOnWarning:        data.FailOnWarning.ValueBool(),
 	}
 }
 
@@ -380,3 +1345,23 @@ func replaceValues(content []byte, substitutions map[string]string) []byte {
 
 	return []byte(contentStr)
 }
+
+// setAttributeDefaults sets the schema defaults of the attributes left null by imports and by the states written before the
+// attributes had defaults, so the plan does not update the resource only to record the defaults.
+func setAttributeDefaults(data *FhirResourceModel) {
+	if data.UpdateMethod.IsNull() {
+		data.UpdateMethod = types.StringValue(updateMethodPut)
+	}
+	if data.OnExternalDelete.IsNull() {
+		data.OnExternalDelete = types.StringValue("recreate")
+	}
+	if data.OnIdMismatch.IsNull() {
+		data.OnIdMismatch = types.StringValue("error")
+	}
+	if data.CreateMethod.IsNull() {
+		data.CreateMethod = types.StringValue("post")
+	}
+	if data.InjectIdOnUpdate.IsNull() {
+		data.InjectIdOnUpdate = types.BoolValue(true)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
@@ -28,6 +29,8 @@ type FhirResourceDataSource struct {
 type FhirResourceDataSourceModel struct {
 	ResourceId  types.String `tfsdk:"resource_id"`
 	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+	VersionId   types.String `tfsdk:"version_id"`
 
 	// state
 	Resource types.String `tfsdk:"resource"`
@@ -51,6 +54,15 @@ func (d *FhirResourceDataSource) Schema(ctx context.Context, req datasource.Sche
 				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
 				Optional:            true,
 			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "When set, reads this version of the resource (vread) instead of the current one",
+				Optional:            true,
+			},
 			"resource": schema.StringAttribute{
 				MarkdownDescription: "The fhir json as string",
 				Computed:            true,
@@ -88,12 +100,19 @@ func (d *FhirResourceDataSource) Read(ctx context.Context, req datasource.ReadRe
 		return
 	}
 
-	body, shouldReturn := ReadFhirResource(d.providerSettings, data.FhirBaseUrl.ValueStringPointer(), data.ResourceId.ValueString(), &resp.Diagnostics)
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	resourceId := data.ResourceId.ValueString()
+	if data.VersionId.ValueString() != "" {
+		resourceId = fmt.Sprintf("%s/_history/%s", resourceId, data.VersionId.ValueString())
+	}
+
+	body, shouldReturn := ReadFhirResource(ctx, d.providerSettings, data.FhirBaseUrl.ValueStringPointer(), resourceId, &resp.Diagnostics)
 	if shouldReturn {
 		return
 	}
 
-	data.Resource = types.StringValue(string(body))
+	data.Resource = d.providerSettings.stateBody(body)
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
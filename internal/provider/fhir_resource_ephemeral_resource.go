@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &FhirResourceEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &FhirResourceEphemeralResource{}
+
+func NewFhirResourceEphemeralResource() ephemeral.EphemeralResource {
+	return &FhirResourceEphemeralResource{}
+}
+
+// FhirResourceEphemeralResource defines the ephemeral resource implementation.
+type FhirResourceEphemeralResource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirResourceEphemeralResourceModel describes the ephemeral resource data model.
+type FhirResourceEphemeralResourceModel struct {
+	ResourceId  types.String `tfsdk:"resource_id"`
+	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+	VersionId   types.String `tfsdk:"version_id"`
+
+	// result
+	Resource types.String `tfsdk:"resource"`
+}
+
+func (e *FhirResourceEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_resource"
+}
+
+func (e *FhirResourceEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This ephemeral resource is able to read a fhir resource and return it as a json, like the data source, but without ever writing it to the plan or the state. Requires Terraform 1.10 or later",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the fhir resource, example Medication/08146022-932a-4001-9fe4-928382855ddf",
+				Required:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this ephemeral resource, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "When set, reads this version of the resource (vread) instead of the current one",
+				Optional:            true,
+			},
+			"resource": schema.StringAttribute{
+				MarkdownDescription: "The fhir json as string. Not affected by the state_redaction of the provider, as it is never written to the state",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (e *FhirResourceEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	e.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (e *FhirResourceEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data FhirResourceEphemeralResourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	resourceId := data.ResourceId.ValueString()
+	if data.VersionId.ValueString() != "" {
+		resourceId = fmt.Sprintf("%s/_history/%s", resourceId, data.VersionId.ValueString())
+	}
+
+	body, shouldReturn := ReadFhirResource(ctx, e.providerSettings, data.FhirBaseUrl.ValueStringPointer(), resourceId, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+
+	data.Resource = types.StringValue(string(body))
+
+	// Save data into the ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirResourceExistsDataSource{}
+
+func NewFhirResourceExistsDataSource() datasource.DataSource {
+	return &FhirResourceExistsDataSource{}
+}
+
+// FhirResourceExistsDataSource defines the data source that checks if a resource exists.
+type FhirResourceExistsDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirResourceExistsDataSourceModel describes the data source data model.
+type FhirResourceExistsDataSourceModel struct {
+	ResourceId       types.String `tfsdk:"resource_id"`
+	ResourceType     types.String `tfsdk:"resource_type"`
+	SearchParameters types.Map    `tfsdk:"search_parameters"`
+	FhirBaseUrl      types.String `tfsdk:"fhir_base_url"`
+	Headers          types.Map    `tfsdk:"headers"`
+
+	// state
+	Exists  types.Bool   `tfsdk:"exists"`
+	FoundId types.String `tfsdk:"found_id"`
+}
+
+func (d *FhirResourceExistsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_resource_exists"
+}
+
+func (d *FhirResourceExistsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source checks if a resource exists, either by its id or by a search. Missing (404) and deleted (410) resources do not fail the data source",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the fhir resource, example Medication/08146022-932a-4001-9fe4-928382855ddf. Conflicts with resource_type",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("resource_type")),
+				},
+			},
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the resources to search, example Patient. Conflicts with resource_id",
+				Optional:            true,
+			},
+			"search_parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters used together with resource_type, example `{ \"identifier\" = \"http://example.com|123\" }`",
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.AlsoRequires(path.MatchRoot("resource_type")),
+				},
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"exists": schema.BoolAttribute{
+				MarkdownDescription: "Whether the resource exists or at least one resource matches the search",
+				Computed:            true,
+			},
+			"found_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the resource found, example Medication/08146022-932a-4001-9fe4-928382855ddf. When the search matches several resources, the id of the first one",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirResourceExistsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirResourceExistsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirResourceExistsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	data.Exists = types.BoolValue(false)
+	data.FoundId = types.StringNull()
+
+	if !data.ResourceId.IsNull() {
+		url := fmt.Sprintf("%s/%s", baseUrl, data.ResourceId.ValueString())
+		response, err := DoFhirRequest(ctx, d.providerSettings, "GET", url, nil)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("could not send the GET request using the URL %s", url), err.Error())
+			return
+		}
+		switch {
+		case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		case response.Status[0] == '2':
+			var ref fhirResourceRef
+			if err := json.Unmarshal(response.Body, &ref); err != nil {
+				resp.Diagnostics.AddError(fmt.Sprintf("failed to unmarshal response JSON of the resource %s", data.ResourceId.ValueString()), err.Error())
+				return
+			}
+			data.Exists = types.BoolValue(true)
+			data.FoundId = types.StringValue(fmt.Sprintf("%s/%s", ref.ResourceType, ref.Id))
+		default:
+			resp.Diagnostics.AddError(fmt.Sprintf("the server returned an invalid status for the GET request on the url %s: %s", url, response.Status), response.ErrorDetail())
+			return
+		}
+	} else {
+		searchParameters := make(map[string]string)
+		resp.Diagnostics.Append(data.SearchParameters.ElementsAs(ctx, &searchParameters, true)...)
+		searchParameters["_count"] = "1"
+		searchParameters["_elements"] = "id"
+
+		searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+		body, shouldReturn := SendFhirRequest(ctx, d.providerSettings, "GET", searchUrl, nil, &resp.Diagnostics)
+		if shouldReturn {
+			return
+		}
+		bundle, err := parseBundle(body)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+			return
+		}
+		if ids := bundle.ResourceIds(); len(ids) > 0 {
+			data.Exists = types.BoolValue(true)
+			data.FoundId = types.StringValue(ids[0])
+		}
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
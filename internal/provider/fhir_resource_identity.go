@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithIdentity = &FhirResource{}
+
+// FhirResourceIdentityModel describes the identity of a fhir resource.
+type FhirResourceIdentityModel struct {
+	BaseUrl      types.String `tfsdk:"base_url"`
+	ResourceType types.String `tfsdk:"resource_type"`
+	Id           types.String `tfsdk:"id"`
+}
+
+func (r *FhirResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"base_url": identityschema.StringAttribute{
+				Description:       "The Base URL of the fhir server. Defaults to the value set in the provider",
+				OptionalForImport: true,
+			},
+			"resource_type": identityschema.StringAttribute{
+				Description:       "The type of the resource, example Patient",
+				RequiredForImport: true,
+			},
+			"id": identityschema.StringAttribute{
+				Description:       "The logical id of the resource, example 08146022-932a-4001-9fe4-928382855ddf",
+				RequiredForImport: true,
+			},
+		},
+	}
+}
+
+// setIdentity stores the identity of the resource, when terraform supports resource identities.
+func (r *FhirResource) setIdentity(ctx context.Context, data FhirResourceModel, identity *tfsdk.ResourceIdentity, diag *diag.Diagnostics) {
+	if identity == nil {
+		return
+	}
+
+	baseUrl := normalizeBaseUrl(data.FhirBaseUrl.ValueString())
+	if data.FhirBaseUrl.IsNull() && r.providerSettings != nil {
+		baseUrl = r.providerSettings.FhirBaseUrl
+	}
+	resourceType, id, _ := strings.Cut(data.ResourceId.ValueString(), "/")
+	diag.Append(identity.Set(ctx, FhirResourceIdentityModel{
+		BaseUrl:      types.StringValue(baseUrl),
+		ResourceType: types.StringValue(resourceType),
+		Id:           types.StringValue(id),
+	})...)
+}
+
+// importStateFromIdentity imports the resource from the identity given in an import block.
+func (r *FhirResource) importStateFromIdentity(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	var identity FhirResourceIdentityModel
+	resp.Diagnostics.Append(req.Identity.Get(ctx, &identity)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resourceId := fmt.Sprintf("%s/%s", identity.ResourceType.ValueString(), identity.Id.ValueString())
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("resource_id"), resourceId)...)
+
+	// The base url is only kept in the state when it differs from the one of the provider.
+	baseUrl := normalizeBaseUrl(identity.BaseUrl.ValueString())
+	if baseUrl != "" && (r.providerSettings == nil || baseUrl != r.providerSettings.FhirBaseUrl) {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("fhir_base_url"), baseUrl)...)
+	}
+}
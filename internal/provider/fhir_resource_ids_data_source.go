@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// defaultMaxResults is the safety cap on the amount of resources returned by a paged search.
+const defaultMaxResults = 10000
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirResourceIdsDataSource{}
+
+func NewFhirResourceIdsDataSource() datasource.DataSource {
+	return &FhirResourceIdsDataSource{}
+}
+
+// FhirResourceIdsDataSource defines the data source that lists the ids of all the resources matching a search.
+type FhirResourceIdsDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirResourceIdsDataSourceModel describes the data source data model.
+type FhirResourceIdsDataSourceModel struct {
+	ResourceType     types.String `tfsdk:"resource_type"`
+	SearchParameters types.Map    `tfsdk:"search_parameters"`
+	FhirBaseUrl      types.String `tfsdk:"fhir_base_url"`
+	Headers          types.Map    `tfsdk:"headers"`
+	Strict           types.Bool   `tfsdk:"strict"`
+	MaxResults       types.Int64  `tfsdk:"max_results"`
+
+	// state
+	Ids types.List `tfsdk:"ids"`
+}
+
+func (d *FhirResourceIdsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_resource_ids"
+}
+
+func (d *FhirResourceIdsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source lists the ids of all the resources of a type, optionally filtered by search parameters, following the next links of the search until the last page",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the resources to list, example Patient",
+				Required:            true,
+			},
+			"search_parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters filtering the resources, example `{ \"active\" = \"true\" }`. The names and values are url encoded by the provider",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"strict": schema.BoolAttribute{
+				MarkdownDescription: "Sends `Prefer: handling=strict`, so the server rejects unknown or unsupported search parameters instead of ignoring them. Issues reported by the server in the search result also fail the data source",
+				Optional:            true,
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The data source fails when the search returns more resources than this. Defaults to %d", defaultMaxResults),
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The ids of the resources, example Patient/08146022-932a-4001-9fe4-928382855ddf",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirResourceIdsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirResourceIdsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirResourceIdsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	searchParameters := make(map[string]string)
+	resp.Diagnostics.Append(data.SearchParameters.ElementsAs(ctx, &searchParameters, true)...)
+	searchParameters["_elements"] = "id"
+
+	maxResults := int64(defaultMaxResults)
+	if !data.MaxResults.IsNull() {
+		maxResults = data.MaxResults.ValueInt64()
+	}
+
+	ctx = strictSearchContext(ctx, data.Strict.ValueBool())
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+	ids := []string{}
+	shouldReturn := searchAllPages(ctx, d.providerSettings, searchUrl, maxResults, &resp.Diagnostics, func(bundle *FhirBundle) {
+		if data.Strict.ValueBool() {
+			checkSearchOutcomes(bundle, searchUrl, &resp.Diagnostics)
+		}
+		ids = append(ids, bundle.ResourceIds()...)
+	})
+	if shouldReturn || resp.Diagnostics.HasError() {
+		return
+	}
+
+	idsList, diags := types.ListValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+	data.Ids = idsList
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ list.ListResource = &FhirResourceListResource{}
+var _ list.ListResourceWithConfigure = &FhirResourceListResource{}
+
+func NewFhirResourceListResource() list.ListResource {
+	return &FhirResourceListResource{}
+}
+
+// FhirResourceListResource lists the fhir resources of a type for terraform query.
+type FhirResourceListResource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirResourceListModel describes the configuration of the list blocks.
+type FhirResourceListModel struct {
+	ResourceType     types.String `tfsdk:"resource_type"`
+	SearchParameters types.Map    `tfsdk:"search_parameters"`
+	FhirBaseUrl      types.String `tfsdk:"fhir_base_url"`
+	Headers          types.Map    `tfsdk:"headers"`
+}
+
+func (l *FhirResourceListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_resource"
+}
+
+func (l *FhirResourceListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the fhir resources of a type found on the server, so `terraform query` can generate their import and configuration blocks. Requires Terraform 1.14 or later",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the listed resources, example Patient",
+				Required:            true,
+			},
+			"search_parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters filtering the listed resources, example `{ \"_tag\" = \"http://example.com/tags|terraform\" }`",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the search requests, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (l *FhirResourceListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	l.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected List Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (l *FhirResourceListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var data FhirResourceListModel
+	var diags diag.Diagnostics
+
+	// Read Terraform configuration data into the model
+	diags.Append(req.Config.Get(ctx, &data)...)
+	searchParameters := make(map[string]string)
+	diags.Append(data.SearchParameters.ElementsAs(ctx, &searchParameters, true)...)
+	ctx = withHeadersAttribute(ctx, data.Headers, &diags)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	baseUrl := resolveBaseUrl(l.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		var results int64
+		pageUrl := searchUrl
+		for pageUrl != "" {
+			var diags diag.Diagnostics
+			body, shouldReturn := SendFhirRequest(ctx, l.providerSettings, "GET", pageUrl, nil, &diags)
+			if shouldReturn {
+				push(list.ListResult{Diagnostics: diags})
+				return
+			}
+			bundle, err := parseBundle(body)
+			if err != nil {
+				diags.AddError(fmt.Sprintf("failed to parse the search result of %s", pageUrl), err.Error())
+				push(list.ListResult{Diagnostics: diags})
+				return
+			}
+
+			for _, resource := range bundle.MatchResources() {
+				if req.Limit > 0 && results >= req.Limit {
+					return
+				}
+				results++
+				if !push(l.listResult(ctx, req, data, resource)) {
+					return
+				}
+			}
+
+			nextUrl := nextPageUrl(l.providerSettings, searchUrl, pageUrl, bundle)
+			if nextUrl == pageUrl {
+				diags.AddError(fmt.Sprintf("the search %s returned a next link pointing to the same page", searchUrl), nextUrl)
+				push(list.ListResult{Diagnostics: diags})
+				return
+			}
+			pageUrl = nextUrl
+		}
+	}
+}
+
+// listResult returns the identity of a found resource and, when requested, its attributes with the content of the server,
+// like an imported resource.
+func (l *FhirResourceListResource) listResult(ctx context.Context, req list.ListRequest, data FhirResourceListModel, body json.RawMessage) list.ListResult {
+	result := req.NewListResult(ctx)
+
+	var ref fhirResourceRef
+	if err := json.Unmarshal(body, &ref); err != nil || ref.Id == "" {
+		result.Diagnostics.AddError("found a resource without resourceType or id", string(body))
+		return result
+	}
+	resourceId := fmt.Sprintf("%s/%s", ref.ResourceType, ref.Id)
+	result.DisplayName = resourceId
+
+	baseUrl := resolveBaseUrl(l.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	result.Diagnostics.Append(result.Identity.Set(ctx, FhirResourceIdentityModel{
+		BaseUrl:      types.StringValue(baseUrl),
+		ResourceType: types.StringValue(ref.ResourceType),
+		Id:           types.StringValue(ref.Id),
+	})...)
+
+	if req.IncludeResource {
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root("resource_id"), resourceId)...)
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root("fhir_base_url"), data.FhirBaseUrl)...)
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root("content"), l.providerSettings.stateBody([]byte(importedContent(body, defaultIgnoreFields))))...)
+		result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root("in_sync"), true)...)
+	}
+	return result
+}
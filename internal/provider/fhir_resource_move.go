@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithMoveState = &FhirResource{}
+
+// movableResourceTypes are the resource types of this provider managing a single existing resource on the server, which
+// can be moved to a fhir_resource to take over the whole resource.
+var movableResourceTypes = []string{"fhir_patch", "fhirpath_patch"}
+
+// movedResourceState holds the attributes of the source resource of a move which are kept by the target resource.
+type movedResourceState struct {
+	ResourceId  string  `json:"resource_id"`
+	FhirBaseUrl *string `json:"fhir_base_url"`
+}
+
+func (r *FhirResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: r.moveStateFromPatch,
+		},
+	}
+}
+
+// moveStateFromPatch moves the state of a patch resource, so a resource that was only patched can be managed as a whole
+// without being recreated, and without the reverse patch being applied as on destroy. The content is set from the server
+// by the next refresh, like on import.
+func (r *FhirResource) moveStateFromPatch(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	source := movedSourceState(ctx, req, resp, movableResourceTypes)
+	if source == nil {
+		return
+	}
+	data := FhirResourceModel{
+		ResourceId:  types.StringValue(source.ResourceId),
+		FhirBaseUrl: types.StringPointerValue(source.FhirBaseUrl),
+	}
+	resp.Diagnostics.Append(resp.TargetState.SetAttribute(ctx, path.Root("resource_id"), data.ResourceId)...)
+	resp.Diagnostics.Append(resp.TargetState.SetAttribute(ctx, path.Root("fhir_base_url"), data.FhirBaseUrl)...)
+	r.setIdentity(ctx, data, resp.TargetIdentity, &resp.Diagnostics)
+}
+
+// moveStateFromFhirResource moves the state of a fhir_resource to a patch resource, so the resource stops being managed as
+// a whole without being deleted, and only keeps the changes of the patch. The patch is applied by the next apply.
+func moveStateFromFhirResource(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	source := movedSourceState(ctx, req, resp, []string{"fhir_resource"})
+	if source == nil {
+		return
+	}
+	resp.Diagnostics.Append(resp.TargetState.SetAttribute(ctx, path.Root("resource_id"), types.StringValue(source.ResourceId))...)
+	resp.Diagnostics.Append(resp.TargetState.SetAttribute(ctx, path.Root("fhir_base_url"), types.StringPointerValue(source.FhirBaseUrl))...)
+	resp.Diagnostics.Append(resp.TargetState.SetAttribute(ctx, path.Root("in_sync"), types.BoolValue(false))...)
+}
+
+// movedSourceState reads the state of the source of a move when it is one of the given resource types of this provider,
+// and resets the target state to a null state of its schema, so the attributes can be set one by one. It returns nil when
+// the source is another resource type, or when its state could not be read.
+func movedSourceState(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse, sourceTypes []string) *movedResourceState {
+	sourceType, found := strings.CutPrefix(req.SourceTypeName, "fhirrest_")
+	if !found || !slices.Contains(sourceTypes, sourceType) || !strings.HasSuffix(req.SourceProviderAddress, "/fhirrest") {
+		return nil
+	}
+	if req.SourceRawState == nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("could not move the %s, its state is missing", req.SourceTypeName), "")
+		return nil
+	}
+	var source movedResourceState
+	if err := json.Unmarshal(req.SourceRawState.JSON, &source); err != nil || source.ResourceId == "" {
+		resp.Diagnostics.AddError(fmt.Sprintf("could not read the resource_id of the %s to move", req.SourceTypeName), fmt.Sprint(err))
+		return nil
+	}
+	resp.TargetState.Raw = tftypes.NewValue(resp.TargetState.Schema.Type().TerraformType(ctx), nil)
+	return &source
+}
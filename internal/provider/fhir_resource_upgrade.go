@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// fhirResourceSchemaVersion is the version of the fhir_resource schema, to be increased with the changes needing a state upgrade.
+const fhirResourceSchemaVersion = 1
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithUpgradeState = &FhirResource{}
+
+// FhirResourceModelV0 describes the state of the first version of the fhir_resource schema.
+type FhirResourceModelV0 struct {
+	FilePath       types.String `tfsdk:"file_path"`
+	FileSha256     types.String `tfsdk:"file_sha256"`
+	FhirBaseUrl    types.String `tfsdk:"fhir_base_url"`
+	Substitutions  types.Map    `tfsdk:"substitutions"`
+	ResourceId     types.String `tfsdk:"resource_id"`
+	ResponseSha256 types.String `tfsdk:"response_sha256"`
+}
+
+func (r *FhirResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"file_path":       schema.StringAttribute{Required: true},
+					"file_sha256":     schema.StringAttribute{Optional: true},
+					"fhir_base_url":   schema.StringAttribute{Optional: true},
+					"substitutions":   schema.MapAttribute{ElementType: types.StringType, Optional: true},
+					"resource_id":     schema.StringAttribute{Computed: true},
+					"response_sha256": schema.StringAttribute{Computed: true},
+				},
+			},
+			StateUpgrader: upgradeFhirResourceStateV0,
+		},
+	}
+}
+
+// upgradeFhirResourceStateV0 copies the attributes of the first schema version, leaving the attributes added since then null.
+// The computed ones, like version_id and in_sync, are set by the next refresh.
+func upgradeFhirResourceStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior FhirResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Start from a null state of the current schema, so the attributes can be set one by one.
+	resp.State.Raw = tftypes.NewValue(resp.State.Schema.Type().TerraformType(ctx), nil)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("file_path"), prior.FilePath)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("file_sha256"), prior.FileSha256)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("fhir_base_url"), prior.FhirBaseUrl)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("substitutions"), prior.Substitutions)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("resource_id"), prior.ResourceId)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("response_sha256"), prior.ResponseSha256)...)
+}
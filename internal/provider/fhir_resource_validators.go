@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigValidators = &FhirResource{}
+
+// ConfigValidators checks the combinations of attributes, like the sources of the content, before anything is sent to the server.
+func (r *FhirResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		// The content of the resource comes from exactly one source.
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("file_path"),
+			path.MatchRoot("content"),
+			path.MatchRoot("content_wo"),
+		),
+		// The write-only content is only sent when its version changes.
+		resourcevalidator.RequiredTogether(
+			path.MatchRoot("content_wo"),
+			path.MatchRoot("content_wo_version"),
+		),
+		// A conditional update sends both the creates and the updates as PUT <type>?<criteria>.
+		resourcevalidator.Conflicting(
+			path.MatchRoot("update_criteria"),
+			path.MatchRoot("if_none_exist"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("update_criteria"),
+			path.MatchRoot("update_method"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("update_criteria"),
+			path.MatchRoot("identifier"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("update_criteria"),
+			path.MatchRoot("create_method"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("if_none_exist"),
+			path.MatchRoot("create_method"),
+		),
+	}
+}
@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// withWriteOnlyArguments applies the write-only arguments, only available in the configuration of the create and update, to
+// the settings and the requests of the operation. They are never written to the state.
+func (r *FhirResource) withWriteOnlyArguments(ctx context.Context, config tfsdk.Config, diag *diag.Diagnostics) context.Context {
+	var contentWo types.String
+	diag.Append(config.GetAttribute(ctx, path.Root("content_wo"), &contentWo)...)
+	if !contentWo.IsNull() && !contentWo.IsUnknown() {
+		r.fhirResourceSettings.Content = contentWo.ValueString()
+		r.fhirResourceSettings.WriteOnly = true
+	}
+
+	var headersWo types.Map
+	diag.Append(config.GetAttribute(ctx, path.Root("headers_wo"), &headersWo)...)
+	return withHeadersAttribute(ctx, headersWo, diag)
+}
@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FhirRest{}
+
+func NewFhirRest() resource.Resource {
+	return &FhirRest{}
+}
+
+// FhirRest defines the resource that calls arbitrary endpoints of the server on each lifecycle phase.
+type FhirRest struct {
+	providerSettings *ProviderSettings
+}
+
+type FhirRestModel struct {
+	// from model
+	FhirBaseUrl types.String       `tfsdk:"fhir_base_url"`
+	Create      *FhirRestCallModel `tfsdk:"create"`
+	Update      *FhirRestCallModel `tfsdk:"update"`
+	Delete      *FhirRestCallModel `tfsdk:"delete"`
+
+	//actual state
+	Response types.String `tfsdk:"response"`
+}
+
+// FhirRestCallModel describes the request sent on a lifecycle phase.
+type FhirRestCallModel struct {
+	Method types.String `tfsdk:"method"`
+	Path   types.String `tfsdk:"path"`
+	Body   types.String `tfsdk:"body"`
+}
+
+func restCallSchema(phase string, defaultMethod string, required bool) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: fmt.Sprintf("The request sent when the resource is %s", phase),
+		Required:            required,
+		Optional:            !required,
+		Attributes: map[string]schema.Attribute{
+			"method": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("The http method. Defaults to %s", defaultMethod),
+				Optional:            true,
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The path relative to the base url, including the query string, example `$mark-all-resources-for-reindexing`",
+				Required:            true,
+			},
+			"body": schema.StringAttribute{
+				MarkdownDescription: "The body of the request",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *FhirRest) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rest"
+}
+
+func (r *FhirRest) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Sends arbitrary requests to the server when the resource is created, updated or destroyed, to drive admin endpoints (e.g. HAPI reindexing, Smile CDR module configuration) with the same provider configuration",
+
+		Attributes: map[string]schema.Attribute{
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"create": restCallSchema("created", "POST", true),
+			"update": restCallSchema("updated. When not set nothing is sent on updates", "PUT", false),
+			"delete": restCallSchema("destroyed. When not set nothing is sent on destroy", "DELETE", false),
+			"response": schema.StringAttribute{
+				MarkdownDescription: "The body of the response of the last request sent",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *FhirRest) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	r.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (r *FhirRest) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FhirRestModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	body, shouldReturn := r.call(ctx, data, data.Create, "POST", &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+	data.Response = r.providerSettings.stateBody(body)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirRest) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to refresh, the calls only exist in the state.
+}
+
+func (r *FhirRest) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state FhirRestModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	var data FhirRestModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Response = state.Response
+	if data.Update != nil {
+		body, shouldReturn := r.call(ctx, data, data.Update, "PUT", &resp.Diagnostics)
+		if shouldReturn {
+			return
+		}
+		data.Response = r.providerSettings.stateBody(body)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FhirRest) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FhirRestModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() || data.Delete == nil {
+		return
+	}
+
+	r.call(ctx, data, data.Delete, "DELETE", &resp.Diagnostics)
+}
+
+func (r *FhirRest) call(ctx context.Context, data FhirRestModel, call *FhirRestCallModel, defaultMethod string, diag *diag.Diagnostics) ([]byte, bool) {
+	method := defaultMethod
+	if call.Method.ValueString() != "" {
+		method = strings.ToUpper(call.Method.ValueString())
+	}
+	var requestBody []byte
+	if !call.Body.IsNull() {
+		requestBody = []byte(call.Body.ValueString())
+	}
+
+	baseUrl := resolveBaseUrl(r.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	url := fmt.Sprintf("%s/%s", baseUrl, strings.TrimPrefix(call.Path.ValueString(), "/"))
+	return SendFhirRequest(ctx, r.providerSettings, method, url, requestBody, diag)
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryMinBackoff  = 1 * time.Second
+	defaultRetryMaxBackoff  = 30 * time.Second
+)
+
+// retryableStatusCodes are the statuses returned by overloaded or restarting servers, worth sending the request again.
+var retryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// idempotentMethods are the methods a server can receive twice without side effects, worth sending again after a
+// connection error even when the first request may have reached the server.
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// FhirRetryModel describes the retry provider attribute.
+type FhirRetryModel struct {
+	MaxAttempts types.Int64  `tfsdk:"max_attempts"`
+	MinBackoff  types.String `tfsdk:"min_backoff"`
+	MaxBackoff  types.String `tfsdk:"max_backoff"`
+	Jitter      types.Bool   `tfsdk:"jitter"`
+}
+
+// RetryPolicy defines how often and how long apart failed requests are sent again.
+type RetryPolicy struct {
+	MaxAttempts int64
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	Jitter      bool
+}
+
+func retrySchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Retries of the requests failing with the statuses 429 and 503 carrying a Retry-After header and, for GET, HEAD, PUT, DELETE and the POST sent with an Idempotency-Key, of the requests failing with connection errors or the statuses 429, 502, 503 and 504, using an exponential backoff. The Retry-After header of throttled responses is honoured. By default a request is sent up to 3 times",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"max_attempts": schema.Int64Attribute{
+				MarkdownDescription: "The maximum times a request is sent, including the first attempt. Set it to 1 to disable the retries. Defaults to 3",
+				Optional:            true,
+			},
+			"min_backoff": schema.StringAttribute{
+				MarkdownDescription: "The wait before the first retry, doubled on each further retry, example 500ms. Defaults to 1s",
+				Optional:            true,
+			},
+			"max_backoff": schema.StringAttribute{
+				MarkdownDescription: "The longest wait between two attempts, example 1m. Defaults to 30s",
+				Optional:            true,
+			},
+			"jitter": schema.BoolAttribute{
+				MarkdownDescription: "Randomizes the waits between attempts, spreading the retries of concurrent requests. Defaults to true",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newRetryPolicy builds the retry policy from the provider configuration, using the defaults for the unset values.
+func newRetryPolicy(data *FhirRetryModel, diag *diag.Diagnostics) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		MinBackoff:  defaultRetryMinBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+		Jitter:      true,
+	}
+	if data == nil {
+		return policy
+	}
+	if !data.MaxAttempts.IsNull() {
+		policy.MaxAttempts = max(data.MaxAttempts.ValueInt64(), 1)
+	}
+	policy.MinBackoff = parseDurationAttribute(data.MinBackoff, defaultRetryMinBackoff, "retry.min_backoff", diag)
+	policy.MaxBackoff = parseDurationAttribute(data.MaxBackoff, defaultRetryMaxBackoff, "retry.max_backoff", diag)
+	if !data.Jitter.IsNull() {
+		policy.Jitter = data.Jitter.ValueBool()
+	}
+	return policy
+}
+
+// backoff returns the wait before the given retry, starting at 1 for the first retry.
+func (p RetryPolicy) backoff(retry int64) time.Duration {
+	wait := p.MinBackoff
+	for i := int64(1); i < retry && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, p.MaxBackoff)
+	if p.Jitter && wait > 0 {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+	return wait
+}
+
+// shouldRetry tells if a request failing with the given response or error is worth sending again.
+// Connection errors and gateway errors may happen after the server processed the request, so only the idempotent requests
+// are retried then. Throttled responses carrying a Retry-After tell the request was not processed, they are always retried.
+func shouldRetry(ctx context.Context, method string, response *FhirResponse, err error) bool {
+	if err != nil {
+		return isIdempotentRequest(ctx, method)
+	}
+	if !slices.Contains(retryableStatusCodes, response.StatusCode) {
+		return false
+	}
+	throttled := response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable
+	if throttled && response.Header.Get("Retry-After") != "" {
+		return true
+	}
+	return isIdempotentRequest(ctx, method)
+}
+
+// isIdempotentRequest tells if the request can be sent twice safely, either for its method or because it is a POST
+// carrying an Idempotency-Key the server deduplicates on.
+func isIdempotentRequest(ctx context.Context, method string) bool {
+	if slices.Contains(idempotentMethods, method) {
+		return true
+	}
+	if method != http.MethodPost {
+		return false
+	}
+	headers, _ := ctx.Value(requestHeadersKey{}).(map[string]string)
+	for key, value := range headers {
+		if http.CanonicalHeaderKey(key) == "Idempotency-Key" && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sendWithRetries sends the request, sending it again with an exponential backoff while it fails with transient errors.
+// Throttled requests (429 and 503) wait for the Retry-After sent by the server instead, unless it ends after the deadline of ctx.
+func sendWithRetries(ctx context.Context, providerSettings *ProviderSettings, method string, url string, requestBody []byte) (*FhirResponse, error) {
+	policy := providerSettings.Retry
+	for attempt := int64(1); ; attempt++ {
+		response, err := sendHttpRequest(ctx, providerSettings, method, url, requestBody)
+		if attempt >= policy.MaxAttempts || !shouldRetry(ctx, method, response, err) || ctx.Err() != nil {
+			return response, err
+		}
+
+		wait := policy.backoff(attempt)
+		if err == nil && (response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable) {
+			wait = retryAfter(response.Header, wait)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			tflog.Warn(ctx, fmt.Sprintf("%s %s can not be retried in %s before the operation times out", method, url, wait))
+			return response, err
+		}
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("%s %s failed, retrying in %s: %s", method, url, wait, err.Error()))
+		} else {
+			tflog.Warn(ctx, fmt.Sprintf("%s %s returned %s, retrying in %s", method, url, response.Status, wait))
+		}
+		select {
+		case <-ctx.Done():
+			return response, err
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// FhirBundle holds the parts of a searchset Bundle used by the resources and data sources.
+type FhirBundle struct {
+	ResourceType string            `json:"resourceType"`
+	Type         string            `json:"type"`
+	Total        *int64            `json:"total"`
+	Link         []FhirBundleLink  `json:"link"`
+	Entry        []FhirBundleEntry `json:"entry"`
+}
+
+type FhirBundleLink struct {
+	Relation string `json:"relation"`
+	Url      string `json:"url"`
+}
+
+type FhirBundleEntry struct {
+	FullUrl  string          `json:"fullUrl"`
+	Resource json.RawMessage `json:"resource"`
+	Search   struct {
+		Mode string `json:"mode"`
+	} `json:"search"`
+	Request struct {
+		Method string `json:"method"`
+		Url    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status       string `json:"status"`
+		Location     string `json:"location"`
+		Etag         string `json:"etag"`
+		LastModified string `json:"lastModified"`
+	} `json:"response"`
+}
+
+// fhirResourceRef holds the type and the id of a resource found in a Bundle entry.
+type fhirResourceRef struct {
+	ResourceType string `json:"resourceType"`
+	Id           string `json:"id"`
+}
+
+// NextLink returns the url of the next page of the Bundle, or an empty string on the last page.
+func (b *FhirBundle) NextLink() string {
+	for _, link := range b.Link {
+		if link.Relation == "next" {
+			return link.Url
+		}
+	}
+	return ""
+}
+
+// MatchResources returns the resources of the entries matching the search, leaving out included resources and outcomes.
+func (b *FhirBundle) MatchResources() []json.RawMessage {
+	resources := make([]json.RawMessage, 0, len(b.Entry))
+	for _, entry := range b.Entry {
+		if entry.Search.Mode != "" && entry.Search.Mode != "match" {
+			continue
+		}
+		if len(entry.Resource) == 0 {
+			continue
+		}
+		resources = append(resources, entry.Resource)
+	}
+	return resources
+}
+
+// IncludedResources returns the resources added to the search result by _include and _revinclude.
+func (b *FhirBundle) IncludedResources() []json.RawMessage {
+	resources := make([]json.RawMessage, 0)
+	for _, entry := range b.Entry {
+		if entry.Search.Mode == "include" && len(entry.Resource) > 0 {
+			resources = append(resources, entry.Resource)
+		}
+	}
+	return resources
+}
+
+// Outcomes returns the OperationOutcomes added to the search result, used by lenient servers to report ignored search parameters.
+func (b *FhirBundle) Outcomes() []*FhirOperationOutcome {
+	outcomes := make([]*FhirOperationOutcome, 0)
+	for _, entry := range b.Entry {
+		if entry.Search.Mode != "outcome" {
+			continue
+		}
+		if outcome := parseOperationOutcome(entry.Resource); outcome != nil {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+// ResourceIds returns the ids, in the form <type>/<id>, of the resources of the entries matching the search.
+func (b *FhirBundle) ResourceIds() []string {
+	resources := b.MatchResources()
+	ids := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		var ref fhirResourceRef
+		if err := json.Unmarshal(resource, &ref); err != nil || ref.Id == "" {
+			continue
+		}
+		ids = append(ids, fmt.Sprintf("%s/%s", ref.ResourceType, ref.Id))
+	}
+	return ids
+}
+
+func parseBundle(body []byte) (*FhirBundle, error) {
+	var bundle FhirBundle
+	if err := json.Unmarshal(body, &bundle); err != nil {
+		return nil, err
+	}
+	if bundle.ResourceType != "Bundle" {
+		return nil, fmt.Errorf("expected a Bundle but the server returned a %s", bundle.ResourceType)
+	}
+	return &bundle, nil
+}
+
+// buildSearchUrl returns the search url of the resource type with the url encoded search parameters.
+func buildSearchUrl(baseUrl string, resourceType string, searchParameters map[string]string) string {
+	searchUrl := strings.TrimSuffix(fmt.Sprintf("%s/%s", baseUrl, resourceType), "/")
+	query := encodeSearchParameters(searchParameters)
+	if query == "" {
+		return searchUrl
+	}
+	return fmt.Sprintf("%s?%s", searchUrl, query)
+}
+
+// encodeSearchParameters returns the url encoded query of the search parameters, example identifier=http%3A%2F%2Fexample.com%7C123.
+func encodeSearchParameters(searchParameters map[string]string) string {
+	query := url.Values{}
+	for name, value := range searchParameters {
+		query.Add(name, value)
+	}
+	return query.Encode()
+}
+
+// appendSearchParameter adds a repeatable search parameter, like _include, to a search url once for each value.
+func appendSearchParameter(searchUrl string, name string, values []string) string {
+	if len(values) == 0 {
+		return searchUrl
+	}
+	query := url.Values{name: values}
+	separator := "?"
+	if strings.Contains(searchUrl, "?") {
+		separator = "&"
+	}
+	return searchUrl + separator + query.Encode()
+}
+
+// strictSearchContext returns a context sending Prefer: handling=strict with the search requests when strict is set,
+// asking the server to reject unknown or unsupported search parameters instead of ignoring them.
+func strictSearchContext(ctx context.Context, strict bool) context.Context {
+	if !strict {
+		return ctx
+	}
+	return withRequestHeaders(ctx, map[string]string{"Prefer": "handling=strict"})
+}
+
+// checkSearchOutcomes reports the issues of the OperationOutcomes added to the search result as errors, returning true when any was found.
+func checkSearchOutcomes(bundle *FhirBundle, searchUrl string, diag *diag.Diagnostics) bool {
+	var messages []string
+	for _, outcome := range bundle.Outcomes() {
+		messages = append(messages, outcome.Messages("fatal", "error", "warning")...)
+	}
+	if len(messages) == 0 {
+		return false
+	}
+	diag.AddError(fmt.Sprintf("the server reported issues with the search %s", searchUrl), strings.Join(messages, "\n"))
+	return true
+}
+
+const (
+	// pagingStyleNextLink follows the next links of the Bundles as returned by the server.
+	pagingStyleNextLink = "next_link"
+	// pagingStylePageToken sends the search again with the _page_token of the next link, for the Google Cloud Healthcare API
+	// whose next links may not be reachable as is.
+	pagingStylePageToken = "page_token"
+)
+
+// searchAllPages runs the search and follows the next links of the Bundles until the last page, calling onPage for each page.
+// It stops with an error once more than maxResults resources were returned.
+func searchAllPages(ctx context.Context, providerSettings *ProviderSettings, searchUrl string, maxResults int64, diag *diag.Diagnostics, onPage func(bundle *FhirBundle)) bool {
+	var results int64
+	pageUrl := searchUrl
+	for pageUrl != "" {
+		body, shouldReturn := SendFhirRequest(ctx, providerSettings, "GET", pageUrl, nil, diag)
+		if shouldReturn {
+			return true
+		}
+		bundle, err := parseBundle(body)
+		if err != nil {
+			diag.AddError(fmt.Sprintf("failed to parse the search result of %s", pageUrl), err.Error())
+			return true
+		}
+
+		results += int64(len(bundle.MatchResources()))
+		if results > maxResults {
+			diag.AddError(
+				fmt.Sprintf("the search %s returned more than %d resources", searchUrl, maxResults),
+				"Narrow down the search parameters or increase the maximum of results",
+			)
+			return true
+		}
+		onPage(bundle)
+
+		nextUrl := nextPageUrl(providerSettings, searchUrl, pageUrl, bundle)
+		if nextUrl == pageUrl {
+			diag.AddError(fmt.Sprintf("the search %s returned a next link pointing to the same page", searchUrl), nextUrl)
+			return true
+		}
+		pageUrl = nextUrl
+	}
+	return false
+}
+
+// nextPageUrl returns the url of the page following the page of the search, or an empty string on the last page.
+func nextPageUrl(providerSettings *ProviderSettings, searchUrl string, pageUrl string, bundle *FhirBundle) string {
+	nextLink := resolveLink(pageUrl, bundle.NextLink())
+	if nextLink == "" || providerSettings.PagingStyle != pagingStylePageToken {
+		return nextLink
+	}
+	next, err := url.Parse(nextLink)
+	if err != nil {
+		return nextLink
+	}
+	pageToken := next.Query().Get("_page_token")
+	search, err := url.Parse(searchUrl)
+	if pageToken == "" || err != nil {
+		return nextLink
+	}
+	query := search.Query()
+	query.Set("_page_token", pageToken)
+	search.RawQuery = query.Encode()
+	return search.String()
+}
+
+// resolveLink resolves a possibly relative Bundle link against the url of the page it was found on.
+func resolveLink(pageUrl string, link string) string {
+	if link == "" {
+		return ""
+	}
+	page, err := url.Parse(pageUrl)
+	if err != nil {
+		return link
+	}
+	resolved, err := page.Parse(link)
+	if err != nil {
+		return link
+	}
+	return resolved.String()
+}
@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirSearchDataSource{}
+
+func NewFhirSearchDataSource() datasource.DataSource {
+	return &FhirSearchDataSource{}
+}
+
+// FhirSearchDataSource defines the data source that searches resources of a type.
+type FhirSearchDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirSearchDataSourceModel describes the data source data model.
+type FhirSearchDataSourceModel struct {
+	ResourceType     types.String `tfsdk:"resource_type"`
+	SearchParameters types.Map    `tfsdk:"search_parameters"`
+	FhirBaseUrl      types.String `tfsdk:"fhir_base_url"`
+	Headers          types.Map    `tfsdk:"headers"`
+	Strict           types.Bool   `tfsdk:"strict"`
+	Expect           types.String `tfsdk:"expect"`
+	Include          types.List   `tfsdk:"include"`
+	Revinclude       types.List   `tfsdk:"revinclude"`
+
+	// state
+	Bundle     types.String `tfsdk:"bundle"`
+	Resources  types.List   `tfsdk:"resources"`
+	Total      types.Int64  `tfsdk:"total"`
+	Resource   types.String `tfsdk:"resource"`
+	ResourceId types.String `tfsdk:"resource_id"`
+	Included   types.Map    `tfsdk:"included"`
+}
+
+func (d *FhirSearchDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_search"
+}
+
+func (d *FhirSearchDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source searches the resources of a type and returns the resulting Bundle. Only the first page of the search is returned",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the resources to search, example Patient",
+				Required:            true,
+			},
+			"search_parameters": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The search parameters, example `{ \"identifier\" = \"http://example.com|123\" }`. The names and values are url encoded by the provider",
+				Optional:            true,
+			},
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"strict": schema.BoolAttribute{
+				MarkdownDescription: "Sends `Prefer: handling=strict`, so the server rejects unknown or unsupported search parameters instead of ignoring them. Issues reported by the server in the search result also fail the data source",
+				Optional:            true,
+			},
+			"expect": schema.StringAttribute{
+				MarkdownDescription: "How many matches are expected. With `one` the data source fails unless exactly one resource matches the search, making the result safe to use in references. Defaults to `any`",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf("any", "one")},
+			},
+			"include": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The `_include` parameters of the search, example `[\"MedicationRequest:medication\"]`",
+				Optional:            true,
+			},
+			"revinclude": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The `_revinclude` parameters of the search, example `[\"Provenance:target\"]`",
+				Optional:            true,
+			},
+			"bundle": schema.StringAttribute{
+				MarkdownDescription: "The Bundle returned by the server as json string",
+				Computed:            true,
+			},
+			"resources": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The resources matching the search, each one as json string",
+				Computed:            true,
+			},
+			"total": schema.Int64Attribute{
+				MarkdownDescription: "The total of matches reported by the server. When the server does not report it, the amount of resources returned",
+				Computed:            true,
+			},
+			"included": schema.MapAttribute{
+				ElementType:         basetypes.ListType{ElemType: basetypes.StringType{}},
+				MarkdownDescription: "The resources added by `include` and `revinclude`, grouped by resource type, each one as json string",
+				Computed:            true,
+			},
+			"resource": schema.StringAttribute{
+				MarkdownDescription: "The matching resource as json string, only set when exactly one resource matches the search",
+				Computed:            true,
+			},
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the matching resource, example Organization/08146022-932a-4001-9fe4-928382855ddf. Only set when exactly one resource matches the search",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FhirSearchDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirSearchDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirSearchDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	searchParameters := make(map[string]string)
+	resp.Diagnostics.Append(data.SearchParameters.ElementsAs(ctx, &searchParameters, true)...)
+
+	var include, revinclude []string
+	resp.Diagnostics.Append(data.Include.ElementsAs(ctx, &include, true)...)
+	resp.Diagnostics.Append(data.Revinclude.ElementsAs(ctx, &revinclude, true)...)
+
+	ctx = strictSearchContext(ctx, data.Strict.ValueBool())
+	baseUrl := resolveBaseUrl(d.providerSettings, data.FhirBaseUrl.ValueStringPointer())
+	searchUrl := buildSearchUrl(baseUrl, data.ResourceType.ValueString(), searchParameters)
+	searchUrl = appendSearchParameter(searchUrl, "_include", include)
+	searchUrl = appendSearchParameter(searchUrl, "_revinclude", revinclude)
+	body, shouldReturn := SendFhirRequest(ctx, d.providerSettings, "GET", searchUrl, nil, &resp.Diagnostics)
+	if shouldReturn {
+		return
+	}
+
+	bundle, err := parseBundle(body)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to parse the search result of %s", searchUrl), err.Error())
+		return
+	}
+	if data.Strict.ValueBool() && checkSearchOutcomes(bundle, searchUrl, &resp.Diagnostics) {
+		return
+	}
+
+	matches := bundle.MatchResources()
+	resources := make([]string, 0, len(matches))
+	for _, match := range matches {
+		resources = append(resources, string(match))
+	}
+	total := int64(len(resources))
+	if bundle.Total != nil {
+		total = *bundle.Total
+	}
+	if data.Expect.ValueString() == "one" && (total != 1 || len(resources) != 1) {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("expected exactly one %s matching the search, found %d", data.ResourceType.ValueString(), max(total, int64(len(resources)))),
+			fmt.Sprintf("The search %s must match a single resource when expect is set to one", searchUrl),
+		)
+		return
+	}
+
+	data.Resource = types.StringNull()
+	data.ResourceId = types.StringNull()
+	if len(resources) == 1 && total == 1 {
+		data.Resource = d.providerSettings.stateBody([]byte(resources[0]))
+		if ids := bundle.ResourceIds(); len(ids) == 1 {
+			data.ResourceId = types.StringValue(ids[0])
+		}
+	}
+
+	included := make(map[string][]string)
+	for _, resource := range bundle.IncludedResources() {
+		var ref fhirResourceRef
+		if err := json.Unmarshal(resource, &ref); err == nil {
+			included[ref.ResourceType] = append(included[ref.ResourceType], d.providerSettings.stateBody(resource).ValueString())
+		}
+	}
+	includedMap, diags := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, included)
+	resp.Diagnostics.Append(diags...)
+	data.Included = includedMap
+	if d.providerSettings.StateRedaction.OmitBodies {
+		data.Included = types.MapNull(types.ListType{ElemType: types.StringType})
+	}
+
+	data.Bundle = d.providerSettings.stateBody(body)
+	data.Total = types.Int64Value(total)
+	resourcesList, diags := d.providerSettings.stateBodyList(ctx, resources)
+	resp.Diagnostics.Append(diags...)
+	data.Resources = resourcesList
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// smartOauthUrisExtension is the extension of rest.security holding the OAuth endpoints of SMART on FHIR servers.
+const smartOauthUrisExtension = "http://fhir-registry.smarthealthit.org/StructureDefinition/oauth-uris"
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FhirSecurityDataSource{}
+
+func NewFhirSecurityDataSource() datasource.DataSource {
+	return &FhirSecurityDataSource{}
+}
+
+// FhirSecurityDataSource defines the data source that reads the security metadata of the server.
+type FhirSecurityDataSource struct {
+	providerSettings *ProviderSettings
+}
+
+// FhirSecurityDataSourceModel describes the data source data model.
+type FhirSecurityDataSourceModel struct {
+	FhirBaseUrl types.String `tfsdk:"fhir_base_url"`
+	Headers     types.Map    `tfsdk:"headers"`
+
+	// state
+	Security      types.String `tfsdk:"security"`
+	Cors          types.Bool   `tfsdk:"cors"`
+	Services      types.List   `tfsdk:"services"`
+	Description   types.String `tfsdk:"description"`
+	AuthorizeUrl  types.String `tfsdk:"authorize_url"`
+	TokenUrl      types.String `tfsdk:"token_url"`
+	RegisterUrl   types.String `tfsdk:"register_url"`
+	ManageUrl     types.String `tfsdk:"manage_url"`
+	IntrospectUrl types.String `tfsdk:"introspect_url"`
+	RevokeUrl     types.String `tfsdk:"revoke_url"`
+}
+
+func (d *FhirSecurityDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fhir_security"
+}
+
+func (d *FhirSecurityDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	smartUrl := func(name string) schema.StringAttribute {
+		return schema.StringAttribute{
+			MarkdownDescription: fmt.Sprintf("The %s endpoint declared in the SMART oauth-uris extension", name),
+			Computed:            true,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "This data source reads the security section (`rest.security`) of the CapabilityStatement of the server, including the OAuth endpoints of SMART on FHIR servers",
+
+		Attributes: map[string]schema.Attribute{
+			"fhir_base_url": schema.StringAttribute{
+				MarkdownDescription: "The Base URL of the fhir server. Overrides the value set in the provider (if any set)",
+				Optional:            true,
+			},
+			"headers": schema.MapAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "Headers sent with the requests of this data source, merged over the default_headers of the provider",
+				Optional:            true,
+			},
+			"security": schema.StringAttribute{
+				MarkdownDescription: "The rest.security element as json string",
+				Computed:            true,
+			},
+			"cors": schema.BoolAttribute{
+				MarkdownDescription: "Whether the server adds CORS headers",
+				Computed:            true,
+			},
+			"services": schema.ListAttribute{
+				ElementType:         basetypes.StringType{},
+				MarkdownDescription: "The codes of the supported security services, example SMART-on-FHIR",
+				Computed:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "The description of the security of the server",
+				Computed:            true,
+			},
+			"authorize_url":  smartUrl("authorize"),
+			"token_url":      smartUrl("token"),
+			"register_url":   smartUrl("register"),
+			"manage_url":     smartUrl("manage"),
+			"introspect_url": smartUrl("introspect"),
+			"revoke_url":     smartUrl("revoke"),
+		},
+	}
+}
+
+func (d *FhirSecurityDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	ok := true
+	d.providerSettings, ok = req.ProviderData.(*ProviderSettings)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderSettings, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+}
+
+func (d *FhirSecurityDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FhirSecurityDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = withHeadersAttribute(ctx, data.Headers, &resp.Diagnostics)
+
+	capabilityStatement, _ := ReadCapabilityStatement(ctx, d.providerSettings, data.FhirBaseUrl.ValueStringPointer(), &resp.Diagnostics)
+	if capabilityStatement == nil {
+		return
+	}
+
+	var security *FhirRestSecurity
+	for _, rest := range capabilityStatement.Rest {
+		if rest.Security != nil && (rest.Mode == "" || rest.Mode == "server") {
+			security = rest.Security
+			break
+		}
+	}
+
+	data.Security = types.StringNull()
+	data.Cors = types.BoolNull()
+	data.Description = types.StringNull()
+	smartUrls := map[string]string{}
+	services := []string{}
+	if security != nil {
+		securityJson, _ := json.Marshal(security)
+		data.Security = types.StringValue(string(securityJson))
+		data.Cors = types.BoolPointerValue(security.Cors)
+		if security.Description != "" {
+			data.Description = types.StringValue(security.Description)
+		}
+		for _, service := range security.Service {
+			for _, coding := range service.Coding {
+				services = append(services, coding.Code)
+			}
+		}
+		for _, extension := range security.Extension {
+			if extension.Url != smartOauthUrisExtension {
+				continue
+			}
+			for _, uri := range extension.Extension {
+				smartUrls[uri.Url] = uri.ValueUri
+			}
+		}
+	}
+
+	servicesList, diags := types.ListValueFrom(ctx, types.StringType, services)
+	resp.Diagnostics.Append(diags...)
+	data.Services = servicesList
+	smartUrl := func(name string) types.String {
+		if value, ok := smartUrls[name]; ok {
+			return types.StringValue(value)
+		}
+		return types.StringNull()
+	}
+	data.AuthorizeUrl = smartUrl("authorize")
+	data.TokenUrl = smartUrl("token")
+	data.RegisterUrl = smartUrl("register")
+	data.ManageUrl = smartUrl("manage")
+	data.IntrospectUrl = smartUrl("introspect")
+	data.RevokeUrl = smartUrl("revoke")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -1,39 +1,395 @@
 package provider
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-func ReadFhirResource(providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, diag *diag.Diagnostics) ([]byte, bool) {
-	baseUrl := providerSettings.FhirBaseUrl
-	if resourceBaseUrl != nil {
-		baseUrl = *resourceBaseUrl
+// defaultAsyncPollInterval is used while polling an async request when the server does not send a Retry-After header.
+const defaultAsyncPollInterval = 2 * time.Second
+
+// defaultMaxResponseSize is the largest response body read from the servers when the provider does not configure one.
+const defaultMaxResponseSize = 100 * 1024 * 1024
+
+// defaultAccept is the Accept header of the requests when the provider does not configure one.
+const defaultAccept = "application/fhir+json"
+
+// defaultContentType is the Content-Type header of the requests when the provider does not configure one.
+const defaultContentType = "application/json"
+
+const (
+	tenantModePath   = "path"
+	tenantModeHeader = "header"
+
+	// defaultTenantHeader is the header carrying the tenant when it is not sent in the path.
+	defaultTenantHeader = "X-Tenant-ID"
+)
+
+const (
+	partitionModePath   = "path"
+	partitionModeHeader = "header"
+
+	// defaultPartitionHeader is the header carrying the HAPI / Smile CDR partition when it is not sent in the path.
+	defaultPartitionHeader = "X-Partition-Name"
+)
+
+// defaultRequestIdHeader is the header carrying the id generated for each request.
+const defaultRequestIdHeader = "X-Request-Id"
+
+// FhirResponse holds the parts of a fhir server response used by the resources and data sources.
+type FhirResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	// RequestId is the id sent with the request, empty when the provider does not send request ids.
+	RequestId string
+}
+
+// ErrorDetail returns the body of a failed response together with the request ids, to correlate the failure with the server logs.
+func (r *FhirResponse) ErrorDetail() string {
+	detail := string(r.Body)
+	if r.RequestId != "" {
+		detail += fmt.Sprintf("\n\nRequest id: %s", r.RequestId)
+	}
+	if serverId := r.Header.Get(defaultRequestIdHeader); serverId != "" && serverId != r.RequestId {
+		detail += fmt.Sprintf("\nServer request id: %s", serverId)
+	}
+	return detail
+}
+
+// requestHeadersKey is the context key of the headers added to the requests by withRequestHeaders.
+type requestHeadersKey struct{}
+
+// withRequestHeaders returns a context adding the given headers, on top of the provider default headers,
+// to every request sent with it, including retries and async polls.
+func withRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := make(map[string]string)
+	if existing, ok := ctx.Value(requestHeadersKey{}).(map[string]string); ok {
+		for key, value := range existing {
+			merged[key] = value
+		}
+	}
+	for key, value := range headers {
+		merged[key] = value
 	}
+	return context.WithValue(ctx, requestHeadersKey{}, merged)
+}
+
+// withHeadersAttribute returns a context adding the headers attribute of a resource or data source to its requests.
+func withHeadersAttribute(ctx context.Context, headers types.Map, diag *diag.Diagnostics) context.Context {
+	if headers.IsNull() || headers.IsUnknown() {
+		return ctx
+	}
+	values := make(map[string]string)
+	diag.Append(headers.ElementsAs(ctx, &values, true)...)
+	return withRequestHeaders(ctx, values)
+}
+
+func ReadFhirResource(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, diag *diag.Diagnostics) ([]byte, bool) {
+	baseUrl := resolveBaseUrl(providerSettings, resourceBaseUrl)
 	url := fmt.Sprintf("%s/%s", baseUrl, resourceId)
-	getRequest, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		diag.AddError(fmt.Sprintf("could get the resource request using the URL %s", url), err.Error())
+	return SendFhirRequest(ctx, providerSettings, "GET", url, nil, diag)
+}
+
+// SendFhirRequest sends a request with the provider default headers to the given url and returns the response body.
+// Connection failures and non 2xx responses are reported in diag.
+func SendFhirRequest(ctx context.Context, providerSettings *ProviderSettings, method string, url string, requestBody []byte, diag *diag.Diagnostics) ([]byte, bool) {
+	response, shouldReturn := SendFhirRequestWithResponse(ctx, providerSettings, method, url, requestBody, diag)
+	if shouldReturn {
 		return nil, true
 	}
+	return response.Body, false
+}
+
+// SendFhirRequestWithResponse works like SendFhirRequest, returning the whole response for callers needing its headers.
+func SendFhirRequestWithResponse(ctx context.Context, providerSettings *ProviderSettings, method string, url string, requestBody []byte, diag *diag.Diagnostics) (*FhirResponse, bool) {
+	response, err := DoFhirRequest(ctx, providerSettings, method, url, requestBody)
+	if checkFhirResponse(method, url, response, err, diag) {
+		return nil, true
+	}
+	return response, false
+}
+
+// checkFhirResponse reports the connection failures and the non 2xx responses of a request in diag, returning true when any was found.
+// The issues of an OperationOutcome returned with a 2xx response are reported as warnings.
+func checkFhirResponse(method string, url string, response *FhirResponse, err error, diag *diag.Diagnostics) bool {
+	if err != nil {
+		diag.AddError(fmt.Sprintf("could not send the %s request using the URL %s", method, url), err.Error())
+		return true
+	}
+	if response.Status[0] != '2' {
+		diag.AddError(fmt.Sprintf("the server returned an invalid status for the %s request on the url %s: %s", method, url, response.Status), response.ErrorDetail())
+		return true
+	}
+	reportOutcomeWarnings(method, url, response.Body, diag)
+	return false
+}
+
+// DoFhirRequest sends a request with the provider default headers to the given url and returns the response, whatever its status.
+// Requests failing with transient errors are retried following the retry policy of the provider, and writes are sent
+// one at a time when the provider serializes them. Requests to servers that failed too often in a row are not sent.
+// When the server accepts the request asynchronously (202 with a Content-Location header) the status endpoint is
+// polled until the final response is available.
+func DoFhirRequest(ctx context.Context, providerSettings *ProviderSettings, method string, url string, requestBody []byte) (*FhirResponse, error) {
+	if providerSettings.WriteLock != nil && method != http.MethodGet && method != http.MethodHead {
+		providerSettings.WriteLock.Lock()
+		defer providerSettings.WriteLock.Unlock()
+	}
+	if err := providerSettings.CircuitBreaker.allow(url); err != nil {
+		return nil, err
+	}
+	if err := checkBundleSize(providerSettings, requestBody); err != nil {
+		return nil, err
+	}
+	response, err := sendWithRetries(ctx, providerSettings, method, url, requestBody)
+	if ctx.Err() == nil {
+		// Requests cancelled by terraform or by the operation timeout say nothing about the health of the server.
+		providerSettings.CircuitBreaker.record(url, err != nil || response.StatusCode >= http.StatusInternalServerError)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusAccepted || response.Header.Get("Content-Location") == "" {
+		return response, nil
+	}
+	return pollAsyncRequest(ctx, providerSettings, response)
+}
+
+func sendHttpRequest(ctx context.Context, providerSettings *ProviderSettings, method string, url string, requestBody []byte) (*FhirResponse, error) {
+	var bodyReader io.Reader
+	compressBody := providerSettings.Compression && len(requestBody) >= minGzipBodySize
+	if compressBody {
+		compressed, err := gzipBody(requestBody)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewBuffer(compressed)
+	} else if requestBody != nil {
+		bodyReader = bytes.NewBuffer(requestBody)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", providerSettings.UserAgent)
+	request.Header.Set("Accept", providerSettings.Accept)
 	for key, value := range providerSettings.DefaultHeaders {
-		getRequest.Header.Set(key, value)
+		request.Header.Set(key, value)
+	}
+	request.Header.Set("Content-Type", providerSettings.ContentType)
+	if providerSettings.Tenant != "" && providerSettings.TenantMode == tenantModeHeader {
+		request.Header.Set(providerSettings.TenantHeader, providerSettings.Tenant)
 	}
-	getRequest.Header.Set("Content-Type", "application/json")
-	getResponse, err := providerSettings.Client.Do(getRequest)
+	if providerSettings.Partition != "" && providerSettings.PartitionMode == partitionModeHeader {
+		request.Header.Set(providerSettings.PartitionHeader, providerSettings.Partition)
+	}
+	if compressBody {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	if providerSettings.Compression {
+		request.Header.Set("Accept-Encoding", "gzip")
+	}
+	if providerSettings.Hapi.CacheControl != "" && method == "GET" {
+		request.Header.Set("Cache-Control", providerSettings.Hapi.CacheControl)
+	}
+	if headers, ok := ctx.Value(requestHeadersKey{}).(map[string]string); ok {
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+	}
+	requestId := ""
+	if providerSettings.RequestIdHeader != "" {
+		requestId = uuid.NewString()
+		request.Header.Set(providerSettings.RequestIdHeader, requestId)
+	}
+
+	response, err := providerSettings.Client.Do(request)
 	if err != nil {
-		diag.AddError(fmt.Sprintf("could not delete the resource using the URL %s", url), err.Error())
-		return nil, true
+		if requestId != "" {
+			return nil, fmt.Errorf("%w (request id %s)", err, requestId)
+		}
+		return nil, err
 	}
-	defer getResponse.Body.Close()
+	defer response.Body.Close()
 
-	body, _ := io.ReadAll(getResponse.Body)
-	if getResponse.Status[0] != '2' {
-		diag.AddError(fmt.Sprintf("could not get the resource using the URL %s.", url), fmt.Sprintf("Error code %s. Response: %s", getResponse.Status, string(body)))
-		return nil, true
+	responseReader, err := responseBodyReader(response)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(responseReader, providerSettings.MaxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > providerSettings.MaxResponseSize {
+		return nil, fmt.Errorf("the response of %s %s is larger than the maximum response size of %d bytes", method, url, providerSettings.MaxResponseSize)
+	}
+	tflog.Debug(ctx, fmt.Sprintf("%s %s returned %s", method, url, response.Status), map[string]interface{}{
+		"request_id":        requestId,
+		"server_request_id": response.Header.Get(defaultRequestIdHeader),
+	})
+	return &FhirResponse{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       body,
+		RequestId:  requestId,
+	}, nil
+}
+
+// pollAsyncRequest polls the status endpoint of an accepted async request until the server stops answering 202.
+func pollAsyncRequest(ctx context.Context, providerSettings *ProviderSettings, accepted *FhirResponse) (*FhirResponse, error) {
+	statusUrl := accepted.Header.Get("Content-Location")
+	response := accepted
+	for response.StatusCode == http.StatusAccepted {
+		wait := retryAfter(response.Header, defaultAsyncPollInterval)
+		tflog.Debug(ctx, fmt.Sprintf("async request in progress, polling %s in %s", statusUrl, wait))
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped polling the async request %s: %w", statusUrl, ctx.Err())
+		case <-time.After(wait):
+		}
+
+		var err error
+		response, err = sendHttpRequest(ctx, providerSettings, "GET", statusUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		return response, nil
+	}
+	return unwrapAsyncResponse(response), nil
+}
+
+// unwrapAsyncResponse extracts the response of the original request from the batch-response Bundle returned
+// by the status endpoint once an async request is completed.
+func unwrapAsyncResponse(response *FhirResponse) *FhirResponse {
+	var bundle struct {
+		ResourceType string `json:"resourceType"`
+		Type         string `json:"type"`
+		Entry        []struct {
+			Resource json.RawMessage `json:"resource"`
+			Response struct {
+				Status   string `json:"status"`
+				Location string `json:"location"`
+			} `json:"response"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(response.Body, &bundle); err != nil || bundle.ResourceType != "Bundle" || bundle.Type != "batch-response" || len(bundle.Entry) != 1 {
+		return response
+	}
+	entry := bundle.Entry[0]
+	if strings.TrimSpace(entry.Response.Status) == "" {
+		return response
+	}
+
+	unwrapped := &FhirResponse{
+		Status:    entry.Response.Status,
+		Header:    http.Header{},
+		Body:      entry.Resource,
+		RequestId: response.RequestId,
+	}
+	unwrapped.StatusCode, _ = strconv.Atoi(strings.Fields(entry.Response.Status)[0])
+	if entry.Response.Location != "" {
+		unwrapped.Header.Set("Location", entry.Response.Location)
+	}
+	return unwrapped
+}
+
+// retryAfter parses the Retry-After header, either in seconds or as an http date, returning fallback when absent or invalid.
+func retryAfter(header http.Header, fallback time.Duration) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return fallback
+}
+
+// resourceIdFromLocation returns the id, in the form <type>/<id>, of the resource a Location header points to,
+// example Patient/123 for http://server/fhir/Patient/123/_history/1. It returns an empty string when the header is empty.
+func resourceIdFromLocation(location string) string {
+	resourceId, _ := parseLocation(location)
+	return resourceId
+}
+
+// parseLocation returns the id, in the form <type>/<id>, and the version of the resource a Location header points to,
+// example Patient/123 and 1 for http://server/fhir/Patient/123/_history/1. The version is empty when the header has none.
+func parseLocation(location string) (string, string) {
+	location = strings.SplitN(location, "?", 2)[0]
+	parts := strings.Split(strings.Trim(location, "/"), "/")
+	versionId := ""
+	if i := slices.Index(parts, "_history"); i >= 0 {
+		if i+1 < len(parts) {
+			versionId = parts[i+1]
+		}
+		parts = parts[:i]
+	}
+	if len(parts) < 2 {
+		return "", ""
+	}
+	return fmt.Sprintf("%s/%s", parts[len(parts)-2], parts[len(parts)-1]), versionId
+}
+
+// resolveBaseUrl returns the resource level base url when set, falling back to the one of the provider.
+// The tenant and partition segments are appended when they are sent in the path.
+func resolveBaseUrl(providerSettings *ProviderSettings, resourceBaseUrl *string) string {
+	baseUrl := providerSettings.FhirBaseUrl
+	if resourceBaseUrl != nil {
+		baseUrl = normalizeBaseUrl(*resourceBaseUrl)
+	}
+	if providerSettings.Tenant != "" && providerSettings.TenantMode == tenantModePath {
+		baseUrl = fmt.Sprintf("%s/%s", strings.TrimSuffix(baseUrl, "/"), url.PathEscape(providerSettings.Tenant))
+	}
+	if providerSettings.Partition != "" && providerSettings.PartitionMode == partitionModePath {
+		baseUrl = fmt.Sprintf("%s/%s", strings.TrimSuffix(baseUrl, "/"), url.PathEscape(providerSettings.Partition))
+	}
+	return baseUrl
+}
+
+// normalizeBaseUrl removes the trailing slashes of a base url, which would lead to double slashes in the request urls
+// that some servers reject.
+func normalizeBaseUrl(baseUrl string) string {
+	return strings.TrimRight(baseUrl, "/")
+}
+
+// checkBaseUrl returns an error when the base url is not an absolute http or https url.
+func checkBaseUrl(baseUrl string) error {
+	parsed, err := url.Parse(baseUrl)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("the scheme must be http or https, got %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("the host is missing")
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("the base url can not have a query or a fragment")
 	}
-	return body, false
+	return nil
 }
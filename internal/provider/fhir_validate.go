@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// validateResource sends the resource to the $validate operation, of the resource when resourceId is set, else of its type.
+// The error issues of the returned OperationOutcome are reported as errors and the other issues as warnings, or the warning
+// issues as errors too with failOnWarning. Servers answering invalid resources with a 4xx OperationOutcome are supported.
+func validateResource(ctx context.Context, providerSettings *ProviderSettings, baseUrl string, resourceType string, resourceId string, body []byte, failOnWarning bool, diag *diag.Diagnostics) ([]byte, bool) {
+	url := fmt.Sprintf("%s/%s/$validate", baseUrl, resourceType)
+	if resourceId != "" {
+		url = fmt.Sprintf("%s/%s/$validate", baseUrl, resourceId)
+	}
+	response, err := DoFhirRequest(ctx, providerSettings, "POST", url, body)
+	if err != nil {
+		diag.AddError(fmt.Sprintf("could not send the POST request using the URL %s", url), err.Error())
+		return nil, true
+	}
+	outcome := parseOperationOutcome(response.Body)
+	if outcome == nil {
+		if checkFhirResponse("POST", url, response, err, diag) {
+			return nil, true
+		}
+		diag.AddError(fmt.Sprintf("the server did not return an OperationOutcome for the validation on the url %s", url), string(response.Body))
+		return nil, true
+	}
+	tflog.Debug(ctx, fmt.Sprintf("validated the resource %s. Outcome: %s", resourceType, string(response.Body)))
+
+	warningSeverities, errorSeverities := []string{"information", "warning"}, []string{"fatal", "error"}
+	if failOnWarning {
+		warningSeverities, errorSeverities = []string{"information"}, []string{"fatal", "error", "warning"}
+	}
+	if messages := outcome.Messages(warningSeverities...); len(messages) > 0 {
+		diag.AddWarning(fmt.Sprintf("the validation of the %s reported issues", resourceType), strings.Join(messages, "\n"))
+	}
+	if messages := outcome.Messages(errorSeverities...); len(messages) > 0 || response.Status[0] != '2' {
+		diag.AddError(fmt.Sprintf("the %s is not valid, the server answered %s", resourceType, response.Status), strings.Join(messages, "\n"))
+		return response.Body, true
+	}
+	return response.Body, false
+}
+
+// validateOnly validates the content of the file instead of persisting it, recording the outcome of the validation.
+func (r *FhirResource) validateOnly(ctx context.Context, data *FhirResourceModel, diag *diag.Diagnostics) bool {
+	fileContent := r.fhirResourceSettings.readContent(diag)
+	if fileContent == nil {
+		return true
+	}
+	fileContent = replaceValues(fileContent, r.fhirResourceSettings.Substitutions)
+	var file fhirResourceRef
+	if err := json.Unmarshal(fileContent, &file); err != nil || file.ResourceType == "" {
+		diag.AddError(fmt.Sprintf("property resourceType not found in json file %s", r.fhirResourceSettings.source()), "")
+		return true
+	}
+
+	baseUrl := resolveBaseUrl(r.providerSettings, r.fhirResourceSettings.FhirBaseUrl)
+	outcome, shouldReturn := validateResource(ctx, r.providerSettings, baseUrl, file.ResourceType, "", fileContent, r.fhirResourceSettings.FailOnWarning, diag)
+	if shouldReturn {
+		return true
+	}
+	data.ValidationOutcome = types.StringValue(string(outcome))
+	data.ResourceId = types.StringNull()
+	data.ResponseSha256 = types.StringNull()
+	data.ResponseBody = types.StringNull()
+	data.VersionId = types.StringNull()
+	data.LastUpdated = types.StringNull()
+	data.InSync = types.BoolValue(true)
+	return false
+}
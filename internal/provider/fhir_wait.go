@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofhir/fhirpath"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	defaultWaitForTimeout  = 5 * time.Minute
+	defaultWaitForInterval = 5 * time.Second
+)
+
+// FhirWaitForModel describes the wait_for block.
+type FhirWaitForModel struct {
+	Expression types.String `tfsdk:"expression"`
+	Timeout    types.String `tfsdk:"timeout"`
+	Interval   types.String `tfsdk:"interval"`
+}
+
+func waitForBlockSchema() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Re-reads the resource after it was created or updated until the FHIRPath expression evaluates to true. The timeout and interval also apply while waiting for the activation of a Subscription",
+		Attributes: map[string]schema.Attribute{
+			"expression": schema.StringAttribute{
+				MarkdownDescription: "The FHIRPath expression evaluated against the resource read from the server, example `status = 'active'`",
+				Required:            true,
+			},
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("How long to wait for the expression to be true, example `10m`. Defaults to `%s`", defaultWaitForTimeout),
+				Optional:            true,
+			},
+			"interval": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("How long to wait between two reads of the resource, example `10s`. Defaults to `%s`", defaultWaitForInterval),
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// waitForCondition reads the resource until the wait_for expression is true and returns the last body read.
+// It returns nil when no condition is configured or when waiting failed, in which case diag holds the error.
+func waitForCondition(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, waitFor *FhirWaitForModel, diag *diag.Diagnostics) []byte {
+	if waitFor == nil || waitFor.Expression.ValueString() == "" {
+		return nil
+	}
+
+	timeout, interval := waitForDurations(waitFor, diag)
+	expression, err := fhirpath.Compile(waitFor.Expression.ValueString())
+	if err != nil {
+		diag.AddError(fmt.Sprintf("invalid wait_for expression %s", waitFor.Expression.ValueString()), err.Error())
+	}
+	if diag.HasError() {
+		return nil
+	}
+
+	return pollResource(ctx, providerSettings, resourceBaseUrl, resourceId, timeout, interval, diag, func(body []byte) (bool, string, error) {
+		result, err := expression.Evaluate(body)
+		if err != nil {
+			return false, "", fmt.Errorf("could not evaluate the wait_for expression: %w", err)
+		}
+		return result.AnyTrue(), fmt.Sprintf("the expression %s evaluates to %s", waitFor.Expression.ValueString(), result.String()), nil
+	})
+}
+
+// waitForSubscriptionActivation reads a Subscription until the server finishes the handshake and moves it out of
+// the requested status. A Subscription ending in the error or off status is reported as an error.
+func waitForSubscriptionActivation(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, waitFor *FhirWaitForModel, diag *diag.Diagnostics) []byte {
+	timeout, interval := waitForDurations(waitFor, diag)
+	if diag.HasError() {
+		return nil
+	}
+
+	return pollResource(ctx, providerSettings, resourceBaseUrl, resourceId, timeout, interval, diag, func(body []byte) (bool, string, error) {
+		var subscription struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &subscription); err != nil {
+			return false, "", fmt.Errorf("failed to unmarshal the Subscription: %w", err)
+		}
+		switch subscription.Status {
+		case "requested":
+			return false, "the Subscription status is still requested", nil
+		case "error", "off":
+			return false, "", fmt.Errorf("the server set the Subscription status to %s: %s", subscription.Status, subscription.Error)
+		}
+		return true, "", nil
+	})
+}
+
+func waitForDurations(waitFor *FhirWaitForModel, diag *diag.Diagnostics) (time.Duration, time.Duration) {
+	if waitFor == nil {
+		return defaultWaitForTimeout, defaultWaitForInterval
+	}
+	timeout := parseDurationAttribute(waitFor.Timeout, defaultWaitForTimeout, "wait_for.timeout", diag)
+	interval := parseDurationAttribute(waitFor.Interval, defaultWaitForInterval, "wait_for.interval", diag)
+	return timeout, interval
+}
+
+// pollResource reads the resource every interval until check reports it is done, returning the last body read.
+// check returns a description of the current state which is used in the logs and in the timeout error.
+func pollResource(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, timeout time.Duration, interval time.Duration, diag *diag.Diagnostics, check func(body []byte) (bool, string, error)) []byte {
+	deadline := time.Now().Add(timeout)
+	for {
+		body, shouldReturn := ReadFhirResource(ctx, providerSettings, resourceBaseUrl, resourceId, diag)
+		if shouldReturn {
+			return nil
+		}
+		done, state, err := check(body)
+		if err != nil {
+			diag.AddError(fmt.Sprintf("error while waiting for the resource %s", resourceId), err.Error())
+			return nil
+		}
+		if done {
+			return body
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			diag.AddError(fmt.Sprintf("timeout while waiting for the resource %s", resourceId), fmt.Sprintf("Still waiting after %s: %s", timeout, state))
+			return nil
+		}
+		tflog.Debug(ctx, fmt.Sprintf("waiting for the resource %s, %s. Reading again in %s", resourceId, state, interval))
+		select {
+		case <-ctx.Done():
+			diag.AddError(fmt.Sprintf("stopped waiting for the resource %s", resourceId), ctx.Err().Error())
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// parseDurationAttribute parses a duration string attribute, returning fallback when it is not set.
+func parseDurationAttribute(value types.String, fallback time.Duration, attributeName string, diag *diag.Diagnostics) time.Duration {
+	if value.ValueString() == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		diag.AddError(fmt.Sprintf("invalid duration on %s: %s", attributeName, value.ValueString()), err.Error())
+		return fallback
+	}
+	return duration
+}
+
+// waitForDeletion reads the resource until the server answers 404 or 410, for servers deleting asynchronously.
+// It waits until the context is done, which is bound by the delete timeout of the resource.
+func waitForDeletion(ctx context.Context, providerSettings *ProviderSettings, resourceBaseUrl *string, resourceId string, diag *diag.Diagnostics) {
+	url := fmt.Sprintf("%s/%s", resolveBaseUrl(providerSettings, resourceBaseUrl), resourceId)
+	for {
+		response, err := DoFhirRequest(ctx, providerSettings, "GET", url, nil)
+		if err == nil && isDeletedResponse(response) {
+			return
+		}
+		if checkFhirResponse("GET", url, response, err, diag) {
+			return
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("the resource %s is still returned after the delete. Reading again in %s", resourceId, defaultWaitForInterval))
+		select {
+		case <-ctx.Done():
+			diag.AddError(fmt.Sprintf("the resource %s is still returned by the server after the delete", resourceId), ctx.Err().Error())
+			return
+		case <-time.After(defaultWaitForInterval):
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"encoding/json"
+
+	fhirpathtypes "github.com/gofhir/fhirpath/types"
+)
+
+// fhirPathValueToJson converts a value of a FHIRPath result back to json. Dates, times and quantities become strings.
+func fhirPathValueToJson(value fhirpathtypes.Value) json.RawMessage {
+	var encoded []byte
+	switch typed := value.(type) {
+	case *fhirpathtypes.ObjectValue:
+		return typed.Data()
+	case fhirpathtypes.String:
+		encoded, _ = json.Marshal(typed.Value())
+	case fhirpathtypes.Boolean:
+		encoded, _ = json.Marshal(typed.Bool())
+	case fhirpathtypes.Integer:
+		encoded, _ = json.Marshal(typed.Value())
+	case fhirpathtypes.Decimal:
+		encoded = []byte(typed.String())
+	default:
+		encoded, _ = json.Marshal(typed.String())
+	}
+	return encoded
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofhir/fhirpath"
+	fhirpathtypes "github.com/gofhir/fhirpath/types"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &FhirPathFunction{}
+
+func NewFhirPathFunction() function.Function {
+	return &FhirPathFunction{}
+}
+
+// FhirPathFunction defines the function that evaluates a FHIRPath expression on a resource.
+type FhirPathFunction struct{}
+
+func (f *FhirPathFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "fhirpath"
+}
+
+func (f *FhirPathFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Evaluates a FHIRPath expression on a resource",
+		MarkdownDescription: "Evaluates a FHIRPath expression on a resource json string and returns the items of the result. Strings are returned as is, the other items as json strings, so elements can be decoded with `jsondecode`",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "resource_json",
+				MarkdownDescription: "The resource as json string, example the resource attribute of the fhirrest_fhir_resource data source",
+			},
+			function.StringParameter{
+				Name:                "expression",
+				MarkdownDescription: "The FHIRPath expression, example `Patient.name.where(use = 'official').family`",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *FhirPathFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var resourceJson, expression string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &resourceJson, &expression))
+	if resp.Error != nil {
+		return
+	}
+
+	result, err := fhirpath.Evaluate([]byte(resourceJson), expression)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("could not evaluate the expression %s: %s", expression, err.Error()))
+		return
+	}
+
+	items := make([]string, 0, result.Count())
+	for _, value := range result {
+		if text, ok := value.(fhirpathtypes.String); ok {
+			items = append(items, text.Value())
+			continue
+		}
+		items = append(items, string(fhirPathValueToJson(value)))
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, items))
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &MergeFunction{}
+
+func NewMergeFunction() function.Function {
+	return &MergeFunction{}
+}
+
+// MergeFunction defines the function that deep merges two json documents.
+type MergeFunction struct{}
+
+func (f *MergeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge"
+}
+
+func (f *MergeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Deep merges two FHIR json documents",
+		MarkdownDescription: "Deep merges an override into a FHIR json document following the JSON Merge Patch rules (RFC 7386): the members of the objects are merged recursively, arrays and other values are replaced and the members set to null in the override are removed",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "json",
+				MarkdownDescription: "The FHIR json document, example the content of a resource file shared by the environments",
+			},
+			function.StringParameter{
+				Name:                "override_json",
+				MarkdownDescription: "The json object merged into the document, example `jsonencode({ address = \"https://test.example.com/fhir\" })`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *MergeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document, override string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &document, &override))
+	if resp.Error != nil {
+		return
+	}
+
+	documentValue, err := decodeJsonNumbers([]byte(document))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+	overrideValue, err := decodeJsonNumbers([]byte(override))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+
+	merged, err := encodeJson(mergeJson(documentValue, overrideValue))
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(merged)))
+}
+
+// mergeJson applies a JSON Merge Patch (RFC 7386) to a decoded json value.
+func mergeJson(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeJson(targetObject[key], value)
+	}
+	return targetObject
+}
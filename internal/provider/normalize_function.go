@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &NormalizeFunction{}
+
+func NewNormalizeFunction() function.Function {
+	return &NormalizeFunction{}
+}
+
+// NormalizeFunction defines the function that returns the canonical form of a json document.
+type NormalizeFunction struct{}
+
+func (f *NormalizeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "normalize"
+}
+
+func (f *NormalizeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the canonical form of a FHIR json document",
+		MarkdownDescription: "Returns the canonical form of a FHIR json document, with sorted keys, without insignificant whitespace and without null members, for stable hashing and comparison. Numbers and the nulls of arrays, which are significant in the primitive extensions of FHIR, are kept as is",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "json",
+				MarkdownDescription: "The FHIR json document, example the content of a resource file",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *NormalizeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &document))
+	if resp.Error != nil {
+		return
+	}
+
+	normalized, err := normalizeJson([]byte(document))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(normalized)))
+}
+
+// normalizeJson returns the canonical form of a json document: sorted keys, no insignificant whitespace, no null members
+// and the numbers as written.
+func normalizeJson(document []byte) ([]byte, error) {
+	value, err := decodeJsonNumbers(document)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJson(removeNullMembers(value))
+}
+
+// decodeJsonNumbers decodes a json document keeping the numbers as written, example 1.50 stays 1.50.
+func decodeJsonNumbers(document []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected content after the json value")
+	}
+	return value, nil
+}
+
+// encodeJson encodes a value without escaping the html characters, unlike json.Marshal.
+func encodeJson(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+func removeNullMembers(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, member := range typed {
+			if member == nil {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = removeNullMembers(member)
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = removeNullMembers(item)
+		}
+	}
+	return value
+}
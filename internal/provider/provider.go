@@ -5,18 +5,35 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+	"sync"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 // Ensure FhirRestProvider satisfies various provider interfaces.
 var _ provider.Provider = &FhirRestProvider{}
+var _ provider.ProviderWithFunctions = &FhirRestProvider{}
+var _ provider.ProviderWithEphemeralResources = &FhirRestProvider{}
+var _ provider.ProviderWithListResources = &FhirRestProvider{}
+var _ provider.ProviderWithActions = &FhirRestProvider{}
+var _ provider.ProviderWithValidateConfig = &FhirRestProvider{}
 
 // FhirRestProvider defines the provider implementation.
 type FhirRestProvider struct {
@@ -28,14 +45,82 @@ type FhirRestProvider struct {
 
 // FhirRestProviderModel describes the provider data model.
 type FhirRestProviderModel struct {
-	FhirBaseUrl    types.String `tfsdk:"fhir_base_url"`
-	DefaultHeaders types.Map    `tfsdk:"default_headers"`
+	FhirBaseUrl             types.String                   `tfsdk:"fhir_base_url"`
+	DefaultHeaders          types.Map                      `tfsdk:"default_headers"`
+	RequiredCapabilities    *FhirRequiredCapabilitiesModel `tfsdk:"required_capabilities"`
+	Retry                   *FhirRetryModel                `tfsdk:"retry"`
+	RequestTimeout          types.String                   `tfsdk:"request_timeout"`
+	UserAgent               types.String                   `tfsdk:"user_agent"`
+	RequestIdHeader         types.String                   `tfsdk:"request_id_header"`
+	Compression             types.Bool                     `tfsdk:"compression"`
+	HttpVersion             types.String                   `tfsdk:"http_version"`
+	ConnectionPool          *FhirConnectionPoolModel       `tfsdk:"connection_pool"`
+	SerializeWrites         types.Bool                     `tfsdk:"serialize_writes"`
+	CircuitBreakerThreshold types.Int64                    `tfsdk:"circuit_breaker_threshold"`
+	MaxResponseSize         types.Int64                    `tfsdk:"max_response_size"`
+	UnixSocket              types.String                   `tfsdk:"unix_socket"`
+	DialAddress             types.String                   `tfsdk:"dial_address"`
+	Accept                  types.String                   `tfsdk:"accept"`
+	ContentType             types.String                   `tfsdk:"content_type"`
+	Tenant                  types.String                   `tfsdk:"tenant"`
+	TenantMode              types.String                   `tfsdk:"tenant_mode"`
+	TenantHeader            types.String                   `tfsdk:"tenant_header"`
+	Partition               types.String                   `tfsdk:"partition"`
+	PartitionMode           types.String                   `tfsdk:"partition_mode"`
+	PartitionHeader         types.String                   `tfsdk:"partition_header"`
+	ManagedTag              types.String                   `tfsdk:"managed_tag"`
+	StateRedaction          *FhirStateRedactionModel       `tfsdk:"state_redaction"`
+	Hapi                    *FhirHapiModel                 `tfsdk:"hapi"`
+	PagingStyle             types.String                   `tfsdk:"paging_style"`
+	Azure                   *FhirAzureModel                `tfsdk:"azure"`
 }
 
 type ProviderSettings struct {
 	FhirBaseUrl    string
 	DefaultHeaders map[string]string
 	Client         *http.Client
+	Retry          RetryPolicy
+	UserAgent      string
+	// RequestIdHeader is the header carrying the id generated for each request, empty when no id is sent.
+	RequestIdHeader string
+	Compression     bool
+	// WriteLock serializes the write requests, nil when writes may run concurrently.
+	WriteLock *sync.Mutex
+	// CircuitBreaker fails the requests to servers that failed too often in a row, nil when disabled.
+	CircuitBreaker  *circuitBreaker
+	MaxResponseSize int64
+	Accept          string
+	ContentType     string
+	// Tenant is sent either as a segment appended to the base url or as a header, depending on TenantMode.
+	Tenant       string
+	TenantMode   string
+	TenantHeader string
+	// Partition is the HAPI / Smile CDR partition, sent either as a segment appended to the base url or as a header.
+	Partition       string
+	PartitionMode   string
+	PartitionHeader string
+	// ManagedTagSystem and ManagedTagCode are the meta.tag added on every write, empty when no tag is managed.
+	ManagedTagSystem string
+	ManagedTagCode   string
+	StateRedaction   StateRedaction
+	Hapi             HapiSettings
+	// PagingStyle is how the next pages of the searches are requested.
+	PagingStyle string
+	Azure       AzureSettings
+}
+
+// withPartition returns a copy of the settings targeting the given partition.
+func (s *ProviderSettings) withPartition(partition string) *ProviderSettings {
+	settings := *s
+	settings.Partition = partition
+	return &settings
+}
+
+// withTenant returns a copy of the settings targeting the given tenant.
+func (s *ProviderSettings) withTenant(tenant string) *ProviderSettings {
+	settings := *s
+	settings.Tenant = tenant
+	return &settings
 }
 
 func (p *FhirRestProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -47,7 +132,7 @@ func (p *FhirRestProvider) Schema(ctx context.Context, req provider.SchemaReques
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"fhir_base_url": schema.StringAttribute{
-				MarkdownDescription: "The Base URL of the fhir server. When not set it is mandatory to set it on the fhir_resource",
+				MarkdownDescription: "The Base URL of the fhir server, an absolute http or https url. The trailing slashes are removed. When not set it is mandatory to set it on the fhir_resource",
 				Optional:            true,
 			},
 			"default_headers": schema.MapAttribute{
@@ -55,10 +140,125 @@ func (p *FhirRestProvider) Schema(ctx context.Context, req provider.SchemaReques
 				MarkdownDescription: "The headers of the http requests",
 				Optional:            true,
 			},
+			"required_capabilities": requiredCapabilitiesSchema(),
+			"retry":                 retrySchema(),
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "The maximum duration of each http request, including reading the response, example 2m. Defaults to 60s",
+				Optional:            true,
+			},
+			"user_agent": schema.StringAttribute{
+				MarkdownDescription: "The User-Agent header of the http requests. Defaults to terraform-provider-fhirrest/<version>",
+				Optional:            true,
+			},
+			"request_id_header": schema.StringAttribute{
+				MarkdownDescription: "The header carrying the unique id generated for each request, logged and reported on errors to correlate them with the server logs. Set it to an empty string to not send any id. Defaults to X-Request-Id",
+				Optional:            true,
+			},
+			"compression": schema.BoolAttribute{
+				MarkdownDescription: "Compresses the request bodies larger than 1 KiB with gzip (Content-Encoding: gzip) and asks the server for gzipped responses. The server must accept gzipped requests",
+				Optional:            true,
+			},
+			"http_version": schema.StringAttribute{
+				MarkdownDescription: "The http version of the requests. `1.1` never upgrades to HTTP/2, working around reverse proxies mishandling it. `2` only uses HTTP/2, in clear text (h2c) with http servers, failing with the servers not supporting it. Defaults to `auto`, negotiating HTTP/2 when a https server offers it",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf("auto", "1.1", "2")},
+			},
+			"connection_pool": connectionPoolSchema(),
+			"serialize_writes": schema.BoolAttribute{
+				MarkdownDescription: "Sends the write requests one at a time, whatever the parallelism of terraform, for servers deadlocking or returning version conflicts on concurrent writes. Reads still run concurrently",
+				Optional:            true,
+			},
+			"circuit_breaker_threshold": schema.Int64Attribute{
+				MarkdownDescription: "After this amount of consecutive failed requests (connection errors or 5xx statuses, after the retries) to a server, the remaining requests to it fail immediately, a single request being sent every 30 seconds to detect the server recovered. Set it to 0 to disable. Defaults to 5",
+				Optional:            true,
+			},
+			"max_response_size": schema.Int64Attribute{
+				MarkdownDescription: "The largest response body, in bytes, read from the servers. Larger responses fail the request instead of exhausting the memory of the provider. Defaults to 104857600 (100 MiB)",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"unix_socket": schema.StringAttribute{
+				MarkdownDescription: "The path of a unix socket every connection is opened to, whatever the host of the base url, example /var/run/fhir-proxy.sock. Useful with sidecar proxies",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.ConflictsWith(path.MatchRoot("dial_address"))},
+			},
+			"dial_address": schema.StringAttribute{
+				MarkdownDescription: "The host and port every connection is opened to, whatever the host of the base url, example 127.0.0.1:8443. The base url host is still used for the Host header and tls",
+				Optional:            true,
+			},
+			"accept": schema.StringAttribute{
+				MarkdownDescription: "The Accept header of the requests, example `application/fhir+json; fhirVersion=4.0`. Defaults to `application/fhir+json`",
+				Optional:            true,
+			},
+			"content_type": schema.StringAttribute{
+				MarkdownDescription: "The Content-Type header of the requests, example `application/fhir+json; fhirVersion=4.0` for servers routing the payloads by fhir version. Defaults to `application/json`",
+				Optional:            true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "The tenant of multi-tenant servers, sent as a segment appended to the base url (example <base>/<tenant>/Patient) or as a header, depending on tenant_mode",
+				Optional:            true,
+			},
+			"tenant_mode": schema.StringAttribute{
+				MarkdownDescription: "How the tenant is sent, either `path` or `header`. Defaults to `path`",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(tenantModePath, tenantModeHeader)},
+			},
+			"tenant_header": schema.StringAttribute{
+				MarkdownDescription: "The header carrying the tenant when tenant_mode is `header`. Defaults to X-Tenant-ID",
+				Optional:            true,
+			},
+			"partition": schema.StringAttribute{
+				MarkdownDescription: "The partition of HAPI / Smile CDR partitioned servers, sent with every request, reads and deletes included, as a header or as a segment appended to the base url, depending on partition_mode",
+				Optional:            true,
+			},
+			"partition_mode": schema.StringAttribute{
+				MarkdownDescription: "How the partition is sent, either `header` or `path` (url based partitioning). Defaults to `header`",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(partitionModeHeader, partitionModePath)},
+			},
+			"partition_header": schema.StringAttribute{
+				MarkdownDescription: "The header carrying the partition when partition_mode is `header`, example X-Partition-Id to select the partition by id. Defaults to X-Partition-Name",
+				Optional:            true,
+			},
+			"managed_tag": schema.StringAttribute{
+				MarkdownDescription: "A tag in the form `system|code`, example `https://terraform.io|managed`, added to the meta.tag of every resource written by `fhirrest_fhir_resource`. Updates and deletes of resources lacking the tag fail, protecting resources authored outside of terraform, unless ignore_ownership is set on the resource",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.RegexMatches(regexp.MustCompile(`^[^|]*\|[^|]+$`), "must be in the form system|code")},
+			},
+			"state_redaction": stateRedactionSchema(),
+			"hapi":            hapiSchema(),
+			"azure":           azureSchema(),
+			"paging_style": schema.StringAttribute{
+				MarkdownDescription: "How the next pages of the searches are requested, either `next_link` to follow the next links of the Bundles, or `page_token` to send the search again with the `_page_token` of the next link, for the Google Cloud Healthcare API. Defaults to `next_link`",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.OneOf(pagingStyleNextLink, pagingStylePageToken)},
+			},
 		},
 	}
 }
 
+func (p *FhirRestProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var data FhirRestProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Unknown values are only validated once known, when the provider is configured.
+	if data.FhirBaseUrl.IsNull() || data.FhirBaseUrl.IsUnknown() {
+		return
+	}
+	if err := checkBaseUrl(data.FhirBaseUrl.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("fhir_base_url"),
+			fmt.Sprintf("invalid fhir_base_url %s", data.FhirBaseUrl.ValueString()),
+			fmt.Sprintf("The base url must be an absolute http or https url, example https://example.com/fhir: %s", err.Error()),
+		)
+	}
+}
+
 func (p *FhirRestProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	var data FhirRestProviderModel
 
@@ -68,28 +268,156 @@ func (p *FhirRestProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
+	// The configuration depends on resources not created yet, like the ingress of the server. Terraform 1.9 and later
+	// (with -allow-deferral) plan the resources of this provider once the configuration is known, instead of failing.
+	if !req.Config.Raw.IsFullyKnown() && req.ClientCapabilities.DeferralAllowed {
+		resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+		return
+	}
+
 	headers := make(map[string]string)
 	data.DefaultHeaders.ElementsAs(ctx, &headers, true)
 	settings := &ProviderSettings{
-		FhirBaseUrl:    data.FhirBaseUrl.ValueString(),
-		DefaultHeaders: headers,
-		Client:         http.DefaultClient,
+		FhirBaseUrl:     normalizeBaseUrl(data.FhirBaseUrl.ValueString()),
+		DefaultHeaders:  headers,
+		Client:          newHttpClient(data, &resp.Diagnostics),
+		Retry:           newRetryPolicy(data.Retry, &resp.Diagnostics),
+		UserAgent:       fmt.Sprintf("terraform-provider-fhirrest/%s", p.version),
+		RequestIdHeader: defaultRequestIdHeader,
+		Compression:     data.Compression.ValueBool(),
+		MaxResponseSize: defaultMaxResponseSize,
+		Accept:          defaultAccept,
+		ContentType:     defaultContentType,
+		Tenant:          data.Tenant.ValueString(),
+		TenantMode:      tenantModePath,
+		TenantHeader:    defaultTenantHeader,
+		Partition:       data.Partition.ValueString(),
+		PartitionMode:   partitionModeHeader,
+		PartitionHeader: defaultPartitionHeader,
+		StateRedaction:  newStateRedaction(ctx, data.StateRedaction, &resp.Diagnostics),
+		Hapi:            newHapiSettings(data.Hapi),
+		PagingStyle:     pagingStyleNextLink,
+		Azure:           newAzureSettings(data.Azure),
+	}
+	if !data.PartitionMode.IsNull() {
+		settings.PartitionMode = data.PartitionMode.ValueString()
+	}
+	if !data.PartitionHeader.IsNull() {
+		settings.PartitionHeader = data.PartitionHeader.ValueString()
+	}
+	if !data.PagingStyle.IsNull() {
+		settings.PagingStyle = data.PagingStyle.ValueString()
+	}
+	if !data.TenantMode.IsNull() {
+		settings.TenantMode = data.TenantMode.ValueString()
+	}
+	if !data.TenantHeader.IsNull() {
+		settings.TenantHeader = data.TenantHeader.ValueString()
+	}
+	if !data.Accept.IsNull() {
+		settings.Accept = data.Accept.ValueString()
+	}
+	if !data.ContentType.IsNull() {
+		settings.ContentType = data.ContentType.ValueString()
+	}
+	if !data.ManagedTag.IsNull() {
+		settings.ManagedTagSystem, settings.ManagedTagCode, _ = strings.Cut(data.ManagedTag.ValueString(), "|")
+	}
+	if !data.MaxResponseSize.IsNull() {
+		settings.MaxResponseSize = data.MaxResponseSize.ValueInt64()
+	}
+	settings.CircuitBreaker = newCircuitBreaker(defaultCircuitBreakerThreshold)
+	if !data.CircuitBreakerThreshold.IsNull() {
+		settings.CircuitBreaker = newCircuitBreaker(data.CircuitBreakerThreshold.ValueInt64())
+	}
+	if data.SerializeWrites.ValueBool() {
+		settings.WriteLock = &sync.Mutex{}
+	}
+	if !data.UserAgent.IsNull() {
+		settings.UserAgent = data.UserAgent.ValueString()
+	}
+	if !data.RequestIdHeader.IsNull() {
+		settings.RequestIdHeader = data.RequestIdHeader.ValueString()
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The capabilities are only asserted once the server is known, on apply when the base url is unknown during the plan.
+	if data.RequiredCapabilities != nil && !data.FhirBaseUrl.IsUnknown() {
+		assertCapabilities(ctx, settings, data.RequiredCapabilities, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
 	// Example client configuration for data sources and resources
 	resp.DataSourceData = settings
 	resp.ResourceData = settings
+	resp.EphemeralResourceData = settings
+	resp.ListResourceData = settings
+	resp.ActionData = settings
 }
 
 func (p *FhirRestProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewFhirResource,
+		NewFhirProcessMessage,
+		NewFhirPurge,
+		NewFhirPatch,
+		NewFhirPathPatch,
+		NewFhirRest,
 	}
 }
 
 func (p *FhirRestProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewFhirResourceDataSource,
+		NewFhirGraphqlDataSource,
+		NewFhirSearchDataSource,
+		NewFhirCountDataSource,
+		NewFhirResourceExistsDataSource,
+		NewFhirResourceIdsDataSource,
+		NewFhirHistoryDataSource,
+		NewFhirSecurityDataSource,
+		NewFhirRawGetDataSource,
+		NewFhirResolveReferenceDataSource,
+		NewFhirCanonicalDataSource,
+	}
+}
+
+func (p *FhirRestProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewFhirResourceEphemeralResource,
+	}
+}
+
+func (p *FhirRestProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewFhirResourceListResource,
+	}
+}
+
+func (p *FhirRestProvider) Actions(ctx context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewFhirOperationAction,
+	}
+}
+
+func (p *FhirRestProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewBundleResourcesFunction,
+		NewFhirPathFunction,
+		NewReferenceFunction,
+		NewNormalizeFunction,
+		NewMergeFunction,
+		NewSetPathFunction,
+		NewRemovePathFunction,
+		NewUuid5Function,
+		NewReferencesFunction,
+		NewSearchQueryFunction,
+		NewBundleFunction,
+		NewFhirDiffFunction,
 	}
 }
 
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ReferenceFunction{}
+
+func NewReferenceFunction() function.Function {
+	return &ReferenceFunction{}
+}
+
+// ReferenceFunction defines the function that builds a Reference to a resource.
+type ReferenceFunction struct{}
+
+func (f *ReferenceFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "reference"
+}
+
+func (f *ReferenceFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Builds a Reference to a resource",
+		MarkdownDescription: "Builds a Reference json object string to a resource, example `{\"display\":\"Acme\",\"reference\":\"Organization/123\"}`",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "resource_id",
+				MarkdownDescription: "The id of the resource in the form Type/id, example the resource_id attribute of fhirrest_fhir_resource",
+			},
+			function.StringParameter{
+				Name:                "display",
+				MarkdownDescription: "The text of the Reference, left out when null",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ReferenceFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var resourceId string
+	var display types.String
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &resourceId, &display))
+	if resp.Error != nil {
+		return
+	}
+
+	resourceType, id, found := strings.Cut(resourceId, "/")
+	if !found || resourceType == "" || id == "" {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("the resource id %s is not in the form Type/id", resourceId))
+		return
+	}
+
+	reference := map[string]string{"reference": resourceId}
+	if !display.IsNull() {
+		reference["display"] = display.ValueString()
+	}
+	referenceJson, _ := json.Marshal(reference)
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(referenceJson)))
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ReferencesFunction{}
+
+func NewReferencesFunction() function.Function {
+	return &ReferencesFunction{}
+}
+
+// ReferencesFunction defines the function that returns the references of a resource.
+type ReferencesFunction struct{}
+
+func (f *ReferencesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "references"
+}
+
+func (f *ReferencesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the references of a FHIR json document",
+		MarkdownDescription: "Returns the distinct reference values of the Reference elements found anywhere in a FHIR json document, including the contained resources and the resources of Bundles, example `[\"Organization/123\", \"#contained-1\"]`",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "json",
+				MarkdownDescription: "The FHIR json document, example the content of a resource file",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *ReferencesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &document))
+	if resp.Error != nil {
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, findReferences(value, make([]string, 0))))
+}
+
+// findReferences appends the reference members of the objects of a decoded json value, in a stable order and without duplicates.
+func findReferences(value interface{}, references []string) []string {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if reference, ok := typed["reference"].(string); ok && reference != "" && !slices.Contains(references, reference) {
+			references = append(references, reference)
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			references = findReferences(typed[key], references)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			references = findReferences(item, references)
+		}
+	}
+	return references
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &RemovePathFunction{}
+
+func NewRemovePathFunction() function.Function {
+	return &RemovePathFunction{}
+}
+
+// RemovePathFunction defines the function that removes the value at a path of a json document.
+type RemovePathFunction struct{}
+
+func (f *RemovePathFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "remove_path"
+}
+
+func (f *RemovePathFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Removes the value at a path of a FHIR json document",
+		MarkdownDescription: "Removes the value at a dot separated path of a FHIR json document, where numbers are array indexes, example `identifier.1`. Paths not found in the document are ignored",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "json",
+				MarkdownDescription: "The FHIR json document, example the content of a resource file",
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "The dot separated path of the value, example `text`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *RemovePathFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document, path string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &document, &path))
+	if resp.Error != nil {
+		return
+	}
+
+	documentValue, err := decodeJsonNumbers([]byte(document))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+	documentValue, err = removeJsonPath(documentValue, strings.Split(path, "."))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("could not remove the path %s: %s", path, err.Error()))
+		return
+	}
+
+	result, err := encodeJson(documentValue)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(result)))
+}
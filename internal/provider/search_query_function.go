@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SearchQueryFunction{}
+
+func NewSearchQueryFunction() function.Function {
+	return &SearchQueryFunction{}
+}
+
+// SearchQueryFunction defines the function that builds a search query.
+type SearchQueryFunction struct{}
+
+func (f *SearchQueryFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "search_query"
+}
+
+func (f *SearchQueryFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Builds a search query of a resource type",
+		MarkdownDescription: "Builds the search query of a resource type with the url encoded search parameters, example `Patient?identifier=http%3A%2F%2Fexample.com%7C123`, the same way as the search_parameters of the data sources. The query can be used as import id of `fhirrest_fhir_resource` or appended to a base url",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "resource_type",
+				MarkdownDescription: "The type of the resources to search, example Patient",
+			},
+			function.MapParameter{
+				Name:                "search_parameters",
+				ElementType:         types.StringType,
+				MarkdownDescription: "The search parameters, example `{ \"identifier\" = \"http://example.com|123\", \"name:exact\" = \"Doe\" }`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *SearchQueryFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var resourceType string
+	var searchParameters map[string]string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &resourceType, &searchParameters))
+	if resp.Error != nil {
+		return
+	}
+
+	query := resourceType
+	if encoded := encodeSearchParameters(searchParameters); encoded != "" {
+		query = fmt.Sprintf("%s?%s", resourceType, encoded)
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, query))
+}
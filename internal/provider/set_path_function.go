@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SetPathFunction{}
+
+func NewSetPathFunction() function.Function {
+	return &SetPathFunction{}
+}
+
+// SetPathFunction defines the function that sets a value at a path of a json document.
+type SetPathFunction struct{}
+
+func (f *SetPathFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "set_path"
+}
+
+func (f *SetPathFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Sets a value at a path of a FHIR json document",
+		MarkdownDescription: "Sets a value at a dot separated path of a FHIR json document, where numbers are array indexes, example `identifier.0.value`. The missing objects along the path are created, and an index equal to the length of an array appends to it",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "json",
+				MarkdownDescription: "The FHIR json document, example the content of a resource file",
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "The dot separated path of the value, example `address`",
+			},
+			function.StringParameter{
+				Name:                "value_json",
+				MarkdownDescription: "The value as json, example `jsonencode(\"https://test.example.com/fhir\")`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *SetPathFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document, path, valueJson string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &document, &path, &valueJson))
+	if resp.Error != nil {
+		return
+	}
+
+	documentValue, err := decodeJsonNumbers([]byte(document))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+	value, err := decodeJsonNumbers([]byte(valueJson))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("failed to parse the json: %s", err.Error()))
+		return
+	}
+	documentValue, err = setJsonPath(documentValue, strings.Split(path, "."), value)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("could not set the path %s: %s", path, err.Error()))
+		return
+	}
+
+	result, err := encodeJson(documentValue)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(result)))
+}
+
+// setJsonPath sets the value at the path of a decoded json value, returning the updated value.
+func setJsonPath(target interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	switch typed := target.(type) {
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index > len(typed) {
+			return nil, fmt.Errorf("%s is not an index of an array of %d items", path[0], len(typed))
+		}
+		if index == len(typed) {
+			typed = append(typed, nil)
+		}
+		item, err := setJsonPath(typed[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		typed[index] = item
+		return typed, nil
+	case map[string]interface{}:
+		member, err := setJsonPath(typed[path[0]], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		typed[path[0]] = member
+		return typed, nil
+	case nil:
+		return setJsonPath(make(map[string]interface{}), path, value)
+	default:
+		return nil, fmt.Errorf("the element %s is not an object nor an array", path[0])
+	}
+}
+
+// removeJsonPath removes the value at the path of a decoded json value, returning the updated value. Missing paths are ignored.
+func removeJsonPath(target interface{}, path []string) (interface{}, error) {
+	switch typed := target.(type) {
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("%s is not an index of an array", path[0])
+		}
+		if index >= len(typed) {
+			return typed, nil
+		}
+		if len(path) == 1 {
+			return append(typed[:index], typed[index+1:]...), nil
+		}
+		item, err := removeJsonPath(typed[index], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		typed[index] = item
+		return typed, nil
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(typed, path[0])
+			return typed, nil
+		}
+		member, ok := typed[path[0]]
+		if !ok {
+			return typed, nil
+		}
+		member, err := removeJsonPath(member, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		typed[path[0]] = member
+		return typed, nil
+	default:
+		return target, nil
+	}
+}
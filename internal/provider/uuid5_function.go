@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &Uuid5Function{}
+
+// uuid5Namespaces are the namespaces predefined by RFC 4122, usable by name.
+var uuid5Namespaces = map[string]uuid.UUID{
+	"dns":  uuid.NameSpaceDNS,
+	"url":  uuid.NameSpaceURL,
+	"oid":  uuid.NameSpaceOID,
+	"x500": uuid.NameSpaceX500,
+}
+
+func NewUuid5Function() function.Function {
+	return &Uuid5Function{}
+}
+
+// Uuid5Function defines the function that derives a name based UUID.
+type Uuid5Function struct{}
+
+func (f *Uuid5Function) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "uuid5"
+}
+
+func (f *Uuid5Function) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Derives a stable UUID from a name",
+		MarkdownDescription: "Derives a version 5 (name based) UUID from a namespace and a name, so client assigned ids, like the ones of `create_method = \"put\"`, stay the same on every apply",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "namespace",
+				MarkdownDescription: "The namespace, either a UUID or one of the predefined `dns`, `url`, `oid` and `x500`",
+			},
+			function.StringParameter{
+				Name:                "name",
+				MarkdownDescription: "The name the id is derived from, example a business key like `http://example.com/mrn|123`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *Uuid5Function) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var namespace, name string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &namespace, &name))
+	if resp.Error != nil {
+		return
+	}
+
+	namespaceUuid, ok := uuid5Namespaces[namespace]
+	if !ok {
+		var err error
+		if namespaceUuid, err = uuid.Parse(namespace); err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("the namespace %s is neither a UUID nor one of dns, url, oid and x500", namespace))
+			return
+		}
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, uuid.NewSHA1(namespaceUuid, []byte(name)).String()))
+}